@@ -1,5 +1,7 @@
 module github.com/ollieparsley/twitchy-gopher
 
+go 1.13
+
 require (
 	github.com/jarcoal/httpmock v1.0.4
 	github.com/mattn/goveralls v0.0.4 // indirect
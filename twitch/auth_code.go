@@ -0,0 +1,155 @@
+package twitch
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// authStateTTL how long a generated state can be used to complete the flow
+const authStateTTL = 10 * time.Minute
+
+// ErrInvalidState the state returned to the redirect URI was unknown, expired or already used
+var ErrInvalidState = errors.New("twitch: invalid oauth state")
+
+//AuthorizationError the user or Twitch declined the authorization request
+type AuthorizationError struct {
+	Code        string
+	Description string
+}
+
+// Error - the error code and description returned to the redirect URI
+func (e *AuthorizationError) Error() string {
+	if e.Description == "" {
+		return "twitch: authorization failed: " + e.Code
+	}
+	return "twitch: authorization failed: " + e.Code + ": " + e.Description
+}
+
+//ExchangeAuthorizationCodeInput the inputs used to exchange an authorization code for a token
+type ExchangeAuthorizationCodeInput struct {
+	Code        string
+	RedirectURI string
+}
+
+// ExchangeAuthorizationCode - Exchange the code returned to the redirect URI for a user access token
+func (c *Client) ExchangeAuthorizationCode(input *ExchangeAuthorizationCodeInput) (*Token, *ErrorOutput) {
+	return c.ExchangeAuthorizationCodeWithContext(context.Background(), input)
+}
+
+// ExchangeAuthorizationCodeWithContext - the same as ExchangeAuthorizationCode but the request is bound to the context
+func (c *Client) ExchangeAuthorizationCodeWithContext(ctx context.Context, input *ExchangeAuthorizationCodeInput) (*Token, *ErrorOutput) {
+	params := map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          input.Code,
+		"redirect_uri":  input.RedirectURI,
+		"client_id":     c.oauthConfig.ClientID,
+		"client_secret": c.oauthConfig.ClientSecret,
+	}
+	return c.requestToken(withEndpoint(ctx, "ExchangeAuthorizationCode"), params)
+}
+
+//AuthCodeFlow helps a user authorize the application using the authorization code grant.
+//States are kept in memory so the callback must be handled by the same AuthCodeFlow that built the URL.
+type AuthCodeFlow struct {
+	RedirectURI string
+	Scopes      []string
+	ForceVerify bool // Ask the user to authorize again even if they already have
+
+	client *Client
+	mu     sync.Mutex
+	states map[string]time.Time
+}
+
+// NewAuthCodeFlow - create an authorization code flow for the redirect URI and scopes
+func (c *Client) NewAuthCodeFlow(redirectURI string, scopes []string) *AuthCodeFlow {
+	return &AuthCodeFlow{
+		RedirectURI: redirectURI,
+		Scopes:      scopes,
+		client:      c,
+		states:      map[string]time.Time{},
+	}
+}
+
+// AuthCodeURL - the URL to send the user to, with a new CSRF state that is checked when they return
+func (f *AuthCodeFlow) AuthCodeURL() (string, string, error) {
+	state, err := randomState()
+	if err != nil {
+		return "", "", err
+	}
+
+	f.mu.Lock()
+	now := f.client.now()
+	for s, expiry := range f.states {
+		if now.After(expiry) {
+			delete(f.states, s)
+		}
+	}
+	f.states[state] = now.Add(authStateTTL)
+	f.mu.Unlock()
+
+	query := url.Values{}
+	query.Set("client_id", f.client.oauthConfig.ClientID)
+	query.Set("redirect_uri", f.RedirectURI)
+	query.Set("response_type", "code")
+	query.Set("scope", strings.Join(f.Scopes, " "))
+	query.Set("state", state)
+	if f.ForceVerify {
+		query.Set("force_verify", "true")
+	}
+	return f.client.idURL + "authorize?" + query.Encode(), state, nil
+}
+
+// VerifyState - check the state was generated by this flow and hasn't expired, each state can only be used once
+func (f *AuthCodeFlow) VerifyState(state string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	expiry, ok := f.states[state]
+	delete(f.states, state)
+	return ok && !f.client.now().After(expiry)
+}
+
+// Exchange - verify the state and exchange the code for a user access token
+func (f *AuthCodeFlow) Exchange(ctx context.Context, state string, code string) (*Token, error) {
+	if !f.VerifyState(state) {
+		return nil, ErrInvalidState
+	}
+	token, errorOutput := f.client.ExchangeAuthorizationCodeWithContext(ctx, &ExchangeAuthorizationCodeInput{
+		Code:        code,
+		RedirectURI: f.RedirectURI,
+	})
+	if errorOutput != nil {
+		return nil, errorOutput.Err()
+	}
+	return token, nil
+}
+
+// Handler - handle the redirect back from Twitch, callback is given either the new token or the error
+func (f *AuthCodeFlow) Handler(callback func(w http.ResponseWriter, r *http.Request, token *Token, err error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if code := query.Get("error"); code != "" {
+			f.VerifyState(query.Get("state"))
+			callback(w, r, nil, &AuthorizationError{Code: code, Description: query.Get("error_description")})
+			return
+		}
+		token, err := f.Exchange(r.Context(), query.Get("state"), query.Get("code"))
+		callback(w, r, token, err)
+	})
+}
+
+//randomState a random string that can't be guessed by an attacker
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
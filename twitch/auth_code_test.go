@@ -0,0 +1,146 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAuthCodeURL(t *testing.T) {
+	client := NewClient(&OAuthConfig{ClientID: "client-id"}, &http.Client{})
+	flow := client.NewAuthCodeFlow("http://localhost/callback", []string{"channel_read", "channel_editor"})
+	flow.ForceVerify = true
+
+	authURL, state, err := flow.AuthCodeURL()
+	if err != nil {
+		t.Fatalf("AuthCodeURL err should have been nil: %v", err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	if parsed.Host != "id.twitch.tv" || parsed.Path != "/oauth2/authorize" {
+		t.Errorf("AuthCodeURL URL was not the Twitch authorize endpoint: %s", authURL)
+	}
+	query := parsed.Query()
+	expected := map[string]string{
+		"client_id":     "client-id",
+		"redirect_uri":  "http://localhost/callback",
+		"response_type": "code",
+		"scope":         "channel_read channel_editor",
+		"state":         state,
+		"force_verify":  "true",
+	}
+	for key, val := range expected {
+		if query.Get(key) != val {
+			t.Errorf("AuthCodeURL %s was not %q: %q", key, val, query.Get(key))
+		}
+	}
+	if len(state) != 32 {
+		t.Errorf("AuthCodeURL state was not 32 characters: %s", state)
+	}
+
+	_, otherState, _ := flow.AuthCodeURL()
+	if otherState == state {
+		t.Errorf("AuthCodeURL generated the same state twice: %s", state)
+	}
+}
+
+func TestAuthCodeVerifyState(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithClock(func() time.Time { return now }))
+	flow := client.NewAuthCodeFlow("http://localhost/callback", nil)
+
+	_, state, _ := flow.AuthCodeURL()
+	if flow.VerifyState("unknown") {
+		t.Errorf("AuthCodeVerifyState unknown state should not verify")
+	}
+	if !flow.VerifyState(state) {
+		t.Errorf("AuthCodeVerifyState state should verify")
+	}
+	if flow.VerifyState(state) {
+		t.Errorf("AuthCodeVerifyState state should only verify once")
+	}
+
+	_, state, _ = flow.AuthCodeURL()
+	now = now.Add(authStateTTL + time.Second)
+	if flow.VerifyState(state) {
+		t.Errorf("AuthCodeVerifyState expired state should not verify")
+	}
+}
+
+func TestAuthCodeHandler(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+	flow := client.NewAuthCodeFlow("http://localhost/callback", []string{"channel_read"})
+
+	var gotToken *Token
+	var gotErr error
+	handler := flow.Handler(func(w http.ResponseWriter, r *http.Request, token *Token, err error) {
+		gotToken, gotErr = token, err
+	})
+
+	authURL, state, _ := flow.AuthCodeURL()
+	if parsed, _ := url.Parse(authURL); parsed.Host != server.Listener.Addr().String() {
+		t.Errorf("AuthCodeHandler authorize URL did not use the ID URL: %s", authURL)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/callback?code=auth-code&scope=channel_read&state="+state, nil))
+
+	if gotErr != nil {
+		t.Fatalf("AuthCodeHandler err should have been nil: %v", gotErr)
+	}
+	if gotToken.AccessToken != "access-token-1" || gotToken.RefreshToken != "refresh-token" {
+		t.Errorf("AuthCodeHandler token was not correct: %+v", gotToken)
+	}
+	form := fake.forms[0]
+	if form["grant_type"] != "authorization_code" || form["code"] != "auth-code" || form["redirect_uri"] != "http://localhost/callback" || form["client_secret"] != "client-secret" {
+		t.Errorf("AuthCodeHandler token form was not correct: %+v", form)
+	}
+
+	// Replaying the callback must fail
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/callback?code=auth-code&state="+state, nil))
+	if gotErr != ErrInvalidState {
+		t.Errorf("AuthCodeHandler replayed state error was not ErrInvalidState: %v", gotErr)
+	}
+	if len(fake.forms) != 1 {
+		t.Errorf("AuthCodeHandler replayed state should not have exchanged the code: %d", len(fake.forms))
+	}
+}
+
+func TestAuthCodeHandlerErrors(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+	flow := client.NewAuthCodeFlow("http://localhost/callback", nil)
+
+	var gotErr error
+	handler := flow.Handler(func(w http.ResponseWriter, r *http.Request, token *Token, err error) {
+		gotErr = err
+	})
+
+	_, state, _ := flow.AuthCodeURL()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/callback?error=access_denied&error_description=The+user+denied+you+access&state="+state, nil))
+
+	authorizationError := &AuthorizationError{}
+	if !errors.As(gotErr, &authorizationError) {
+		t.Fatalf("AuthCodeHandlerErrors error was not an *AuthorizationError: %v", gotErr)
+	}
+	if authorizationError.Code != "access_denied" || authorizationError.Description != "The user denied you access" {
+		t.Errorf("AuthCodeHandlerErrors authorization error was not correct: %+v", authorizationError)
+	}
+
+	_, state, _ = flow.AuthCodeURL()
+	_, err := flow.Exchange(context.Background(), state, "wrong-code")
+	apiError := &APIError{}
+	if !errors.As(err, &apiError) || apiError.Message != "Invalid authorization code" {
+		t.Errorf("AuthCodeHandlerErrors wrong code error was not the API error: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package twitch
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// minBandwidthBurst the fewest bytes an upload body can read in one go
+const minBandwidthBurst = 1024
+
+//BandwidthLimiter a token bucket of bytes per second that the bodies of upload requests are read through.
+//Uploads sharing a BandwidthLimiter share its bandwidth, so share one between Clients to cap them all together.
+type BandwidthLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+//NewBandwidthLimiter create a bucket that allows bytesPerSecond bytes to be sent each second
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	b := &BandwidthLimiter{now: time.Now}
+	b.SetLimit(bytesPerSecond)
+	b.tokens = b.burst
+	return b
+}
+
+// SetUploadBandwidth - set the limiter the bodies of upload requests are read through, nil disables throttling
+func (c *Client) SetUploadBandwidth(limiter *BandwidthLimiter) {
+	c.uploadBandwidth = limiter
+}
+
+//WithUploadBandwidth cap the bandwidth used by upload requests
+func WithUploadBandwidth(limiter *BandwidthLimiter) ClientOption {
+	return func(c *Client) {
+		c.SetUploadBandwidth(limiter)
+	}
+}
+
+// SetLimit - change the bytes allowed each second, uploads in progress slow down or speed up straight away
+func (b *BandwidthLimiter) SetLimit(bytesPerSecond int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(b.now())
+	b.rate = float64(bytesPerSecond)
+	// A quarter of a second of bytes at a time keeps the rate even without too many small reads
+	b.burst = math.Max(b.rate/4, minBandwidthBurst)
+	b.tokens = math.Min(b.tokens, b.burst)
+}
+
+// WaitN - block until n bytes can be sent or the context is done
+func (b *BandwidthLimiter) WaitN(ctx context.Context, n int) error {
+	delay := b.reserve(n)
+	if delay <= 0 {
+		return nil
+	}
+	return sleepContext(ctx, delay)
+}
+
+//reserve take n bytes from the bucket, going into debt when there aren't enough, and return how long to wait to pay it off
+func (b *BandwidthLimiter) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate <= 0 {
+		return 0
+	}
+	b.refill(b.now())
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+//refund give back bytes that were reserved but not sent, e.g. at the end of a body
+func (b *BandwidthLimiter) refund(n int) {
+	if n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.tokens+float64(n), b.burst)
+}
+
+//refill add the bytes earned since the last refill
+func (b *BandwidthLimiter) refill(now time.Time) {
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = math.Min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+	}
+	b.last = now
+}
+
+//chunk the most bytes a body can read in one go
+func (b *BandwidthLimiter) chunk() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.burst)
+}
+
+//wrap a function wrapping the body of a request so it is read no faster than the limit
+func (b *BandwidthLimiter) wrap(ctx context.Context) func(io.ReadCloser) io.ReadCloser {
+	return func(body io.ReadCloser) io.ReadCloser {
+		return &throttledReader{ReadCloser: body, ctx: ctx, limiter: b}
+	}
+}
+
+//throttledReader reads a request body through a BandwidthLimiter
+type throttledReader struct {
+	io.ReadCloser
+	ctx     context.Context
+	limiter *BandwidthLimiter
+}
+
+// Read - wait for the bandwidth, then read from the body
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if chunk := r.limiter.chunk(); len(p) > chunk {
+		p = p[:chunk]
+	}
+	if err := r.limiter.WaitN(r.ctx, len(p)); err != nil {
+		return 0, err
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.limiter.refund(len(p) - n)
+	return n, err
+}
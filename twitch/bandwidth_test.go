@@ -0,0 +1,115 @@
+package twitch
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+//receiveRate a server that records how fast it receives request bodies
+type receiveRate struct {
+	mu    sync.Mutex
+	bytes int
+	first time.Time
+	last  time.Time
+}
+
+func (r *receiveRate) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	body, _ := ioutil.ReadAll(req.Body)
+	end := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bytes += len(body)
+	if r.first.IsZero() || start.Before(r.first) {
+		r.first = start
+	}
+	if end.After(r.last) {
+		r.last = end
+	}
+	w.Header().Set("Content-Length", "0")
+	w.WriteHeader(200)
+}
+
+// rate - the bytes received per second
+func (r *receiveRate) rate() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return float64(r.bytes) / r.last.Sub(r.first).Seconds()
+}
+
+func TestBandwidthLimiterReserve(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	limiter := NewBandwidthLimiter(8000)
+	limiter.now = func() time.Time { return now }
+
+	if delay := limiter.reserve(2000); delay != 0 {
+		t.Errorf("BandwidthLimiter the burst should not have waited: %s", delay)
+	}
+	if delay := limiter.reserve(2000); delay != 250*time.Millisecond {
+		t.Errorf("BandwidthLimiter the delay was not 250ms: %s", delay)
+	}
+
+	now = now.Add(time.Second)
+	if delay := limiter.reserve(2000); delay != 0 {
+		t.Errorf("BandwidthLimiter the bytes were not refilled: %s", delay)
+	}
+
+	limiter.SetLimit(0)
+	if delay := limiter.reserve(1000000); delay != 0 {
+		t.Errorf("BandwidthLimiter a limit of 0 should not have waited: %s", delay)
+	}
+}
+
+func TestUploadBandwidth(t *testing.T) {
+	received := &receiveRate{}
+	server := httptest.NewServer(received)
+	defer server.Close()
+
+	limit := 256 * 1024
+	client := NewClient(&OAuthConfig{}, nil, WithUploadURL(server.URL+"/"), WithUploadBandwidth(NewBandwidthLimiter(int64(limit))))
+	start := time.Now()
+	_, errorOutput := client.UploadVideoPart(&UploadVideoPartInput{VideoID: "v123456", Part: 1, Body: bytes.NewBuffer(randomVideo(384 * 1024))})
+
+	if errorOutput != nil {
+		t.Fatalf("UploadVideoPart errorOutput should have been nil: %+v", errorOutput)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("UploadVideoPart the upload was not throttled: %s", elapsed)
+	}
+	if rate := received.rate(); rate > 1.25*float64(limit) {
+		t.Errorf("UploadVideoPart the upload was received faster than the limit: %.0f", rate)
+	}
+}
+
+func TestUploadBandwidthShared(t *testing.T) {
+	received := &receiveRate{}
+	server := httptest.NewServer(received)
+	defer server.Close()
+
+	limit := 256 * 1024
+	limiter := NewBandwidthLimiter(int64(limit))
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := NewClient(&OAuthConfig{}, nil, WithUploadURL(server.URL+"/"), WithUploadBandwidth(limiter))
+			client.UploadVideoPart(&UploadVideoPartInput{VideoID: "v123456", Part: 1, Body: bytes.NewBuffer(randomVideo(192 * 1024))})
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("UploadVideoPart the uploads did not share the limit: %s", elapsed)
+	}
+	if rate := received.rate(); rate > 1.25*float64(limit) {
+		t.Errorf("UploadVideoPart the uploads were received faster than the limit: %.0f", rate)
+	}
+}
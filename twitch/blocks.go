@@ -1,6 +1,7 @@
 package twitch
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -15,6 +16,7 @@ type ListBlocksInput struct {
 
 //ListBlocksOutput the array of blocks
 type ListBlocksOutput struct {
+	Total  int64 `json:"_total"`
 	Blocks []BlockUserOutput
 }
 
@@ -42,6 +44,11 @@ type UnblockUserOutput struct{}
 
 // ListBlocks - return a list of users from a users' block list
 func (c *Client) ListBlocks(input *ListBlocksInput) (*ListBlocksOutput, *ErrorOutput) {
+	return c.ListBlocksWithContext(context.Background(), input)
+}
+
+// ListBlocksWithContext - the same as ListBlocks but the request is bound to the context
+func (c *Client) ListBlocksWithContext(ctx context.Context, input *ListBlocksInput) (*ListBlocksOutput, *ErrorOutput) {
 	params := map[string]string{}
 	if input.Limit != 0 {
 		params["limit"] = strconv.Itoa(input.Limit)
@@ -50,20 +57,54 @@ func (c *Client) ListBlocks(input *ListBlocksInput) (*ListBlocksOutput, *ErrorOu
 		params["offset"] = strconv.Itoa(input.Offset)
 	}
 	output := new(ListBlocksOutput)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("users/%d/blocks", input.UserID), params, output)
+	errorOutput := c.sendAPIRequest(ctx, "ListBlocks", "GET", fmt.Sprintf("users/%d/blocks", input.UserID), params, output)
 	return output, errorOutput
 }
 
+// ListBlocksPages - walk every page of a users' block list, fn returns false to stop early
+func (c *Client) ListBlocksPages(input *ListBlocksInput, fn func(page *ListBlocksOutput, lastPage bool) bool) *ErrorOutput {
+	return c.ListBlocksPagesWithContext(context.Background(), input, fn)
+}
+
+// ListBlocksPagesWithContext - the same as ListBlocksPages but the requests are bound to the context
+func (c *Client) ListBlocksPagesWithContext(ctx context.Context, input *ListBlocksInput, fn func(page *ListBlocksOutput, lastPage bool) bool) *ErrorOutput {
+	return c.traceCalls(ctx, "ListBlocksPages", func(ctx context.Context) *ErrorOutput {
+		pageInput := *input
+		pageInput.Limit = int(pageLimit(int64(input.Limit)))
+		for {
+			output, errorOutput := c.ListBlocksWithContext(ctx, &pageInput)
+			if errorOutput != nil {
+				return errorOutput
+			}
+			pageInput.Offset += len(output.Blocks)
+			lastPage := lastOffsetPage(len(output.Blocks), int64(pageInput.Limit), int64(pageInput.Offset), output.Total)
+			if !fn(output, lastPage) || lastPage {
+				return nil
+			}
+		}
+	})
+}
+
 // BlockUser - Block a user (target) on behalf of another user
 func (c *Client) BlockUser(input *BlockUserInput) (*BlockUserOutput, *ErrorOutput) {
+	return c.BlockUserWithContext(context.Background(), input)
+}
+
+// BlockUserWithContext - the same as BlockUser but the request is bound to the context
+func (c *Client) BlockUserWithContext(ctx context.Context, input *BlockUserInput) (*BlockUserOutput, *ErrorOutput) {
 	output := new(BlockUserOutput)
-	errorOutput := c.sendAPIRequest("PUT", fmt.Sprintf("users/%d/blocks/%d", input.UserID, input.TargetUserID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "BlockUser", "PUT", fmt.Sprintf("users/%d/blocks/%d", input.UserID, input.TargetUserID), nil, output)
 	return output, errorOutput
 }
 
 // UnblockUser - Unblock a user (target) on behalf of another user
 func (c *Client) UnblockUser(input *UnblockUserInput) (*UnblockUserOutput, *ErrorOutput) {
+	return c.UnblockUserWithContext(context.Background(), input)
+}
+
+// UnblockUserWithContext - the same as UnblockUser but the request is bound to the context
+func (c *Client) UnblockUserWithContext(ctx context.Context, input *UnblockUserInput) (*UnblockUserOutput, *ErrorOutput) {
 	output := new(UnblockUserOutput)
-	errorOutput := c.sendAPIRequest("DELETE", fmt.Sprintf("users/%d/blocks/%d", input.UserID, input.TargetUserID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "UnblockUser", "DELETE", fmt.Sprintf("users/%d/blocks/%d", input.UserID, input.TargetUserID), nil, output)
 	return output, errorOutput
 }
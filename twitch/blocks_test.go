@@ -110,3 +110,51 @@ func TestUnblockUser(t *testing.T) {
 		t.Errorf("UnblockUser the output was nil")
 	}
 }
+
+func TestListBlocksPages(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	offsets := []string{}
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/users/1/blocks",
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			offsets = append(offsets, query.Get("offset"))
+			if query.Get("limit") != "2" {
+				t.Errorf("ListBlocksPages the limit was not 2: %s", query.Get("limit"))
+			}
+			if query.Get("offset") == "" {
+				return httpmock.NewStringResponse(200, `{"_total":3,"blocks":[{"_id":1},{"_id":2}]}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"_total":3,"blocks":[{"_id":3}]}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	input := &ListBlocksInput{UserID: 1, Limit: 2}
+	ids := []int64{}
+	lastPages := []bool{}
+	errorOutput := client.ListBlocksPages(input, func(page *ListBlocksOutput, lastPage bool) bool {
+		for _, block := range page.Blocks {
+			ids = append(ids, block.ID)
+		}
+		lastPages = append(lastPages, lastPage)
+		return true
+	})
+
+	if errorOutput != nil {
+		t.Errorf("ListBlocksPages errorOutput should have been nil: %+v", errorOutput)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Errorf("ListBlocksPages the blocks of every page were not walked: %v", ids)
+	}
+	if len(offsets) != 2 || offsets[1] != "2" {
+		t.Errorf("ListBlocksPages the offset was not advanced by the page length: %v", offsets)
+	}
+	if len(lastPages) != 2 || lastPages[0] || !lastPages[1] {
+		t.Errorf("ListBlocksPages only the second page should have been the last: %v", lastPages)
+	}
+	if input.Offset != 0 {
+		t.Errorf("ListBlocksPages the input offset should not have changed: %d", input.Offset)
+	}
+}
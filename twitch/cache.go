@@ -0,0 +1,183 @@
+package twitch
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// privateEndpoints the paths of the authenticated channel and user, their responses hold the stream key and email
+// so they are never cached, e.g. in plain text by a FileCache
+var privateEndpoints = []string{"channel", "user"}
+
+//CacheEntry a cached response to a GET request
+type CacheEntry struct {
+	Resource string // e.g. "channel/1234", mutating calls to the same resource remove the entry
+	ETag     string
+	Header   http.Header
+	Body     []byte
+	Expiry   time.Time // the response is used without asking Twitch until then, afterwards it is revalidated with the ETag
+}
+
+//Cache stores the responses to GET requests, the keys identify the URL and the token it was requested with.
+//Caches are best effort, a backend that fails to store an entry should behave as if it was never stored.
+//Only API requests are cached, never the token endpoints or the authenticated channel and user.
+type Cache interface {
+	Get(key string) *CacheEntry
+	Set(key string, entry *CacheEntry)
+	Invalidate(resource string)
+}
+
+// SetCache - cache the responses to GET requests for ttl, nil disables caching
+func (c *Client) SetCache(cache Cache, ttl time.Duration) {
+	c.cache = cache
+	c.cacheTTL = ttl
+}
+
+//WithCache cache the responses to GET requests for ttl, with a ttl of 0 every response is revalidated with its ETag
+func WithCache(cache Cache, ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.SetCache(cache, ttl)
+	}
+}
+
+//noCacheContextKey the context key marking a request that must not be answered from the cache
+type noCacheContextKey struct{}
+
+//withoutCache ask Twitch even when the cache has a fresh response, the response is still cached
+func withoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheContextKey{}, true)
+}
+
+//cacheable whether the response to the request can be cached
+func (c *Client) cacheable(req *http.Request) bool {
+	if req.Method != "GET" || !strings.HasPrefix(req.URL.String(), c.apiURL) {
+		return false
+	}
+	path := strings.TrimPrefix(req.URL.String(), c.apiURL)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	return !containsString(privateEndpoints, path)
+}
+
+//storeResponse cache the body of a successful GET request, unless it can never be reused
+func (c *Client) storeResponse(req *http.Request, header http.Header, body []byte) {
+	etag := header.Get("ETag")
+	if c.cacheTTL <= 0 && etag == "" {
+		return
+	}
+	c.cache.Set(cacheKey(req), &CacheEntry{
+		Resource: cacheResource(req),
+		ETag:     etag,
+		Header:   header,
+		Body:     body,
+		Expiry:   c.now().Add(c.cacheTTL),
+	})
+}
+
+//cacheKey the URL of the request and a hash of the credentials it is sent with, so responses aren't shared between tokens
+func cacheKey(req *http.Request) string {
+	identity := sha256.Sum256([]byte(req.Header.Get("Authorization") + "\n" + req.Header.Get("Client-ID")))
+	return req.URL.String() + " " + hex.EncodeToString(identity[:8])
+}
+
+//cacheResource the channel or user the request is about, or its path when it isn't about one
+func cacheResource(req *http.Request) string {
+	ids := RequestIDs(req)
+	if id, ok := ids["channel_id"]; ok {
+		return "channel/" + id
+	}
+	if id, ok := ids["user_id"]; ok {
+		return "user/" + id
+	}
+	return req.URL.Path
+}
+
+//cachedResponse a response made from a cache entry, for response metadata and tracing
+func cachedResponse(entry *CacheEntry) *http.Response {
+	return &http.Response{
+		StatusCode: 200,
+		Header:     entry.Header,
+	}
+}
+
+//MemoryCache a Cache that keeps the most recently used entries in memory
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+//memoryCacheItem an entry in the order of the MemoryCache
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+//NewMemoryCache create a cache that keeps at most size entries, the least recently used is removed first
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Get - the entry for the key, or nil
+func (m *MemoryCache) Get(key string) *CacheEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+	m.order.MoveToFront(element)
+	entry := *element.Value.(*memoryCacheItem).entry
+	return &entry
+}
+
+// Set - store the entry, removing the least recently used entry when the cache is full
+func (m *MemoryCache) Set(key string, entry *CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(element)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for m.size > 0 && m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Invalidate - remove every entry for the resource
+func (m *MemoryCache) Invalidate(resource string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, element := range m.entries {
+		if element.Value.(*memoryCacheItem).entry.Resource == resource {
+			m.order.Remove(element)
+			delete(m.entries, key)
+		}
+	}
+}
+
+// Len - the number of entries in the cache
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
@@ -0,0 +1,209 @@
+package twitch
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestCacheFresh(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"Playing"}`))
+
+	now := time.Unix(1500000000, 0)
+	client := NewClient(&OAuthConfig{AccessToken: "access-token"}, &http.Client{},
+		WithCache(NewMemoryCache(10), time.Minute),
+		WithClock(func() time.Time { return now }),
+	)
+
+	for i := 0; i < 3; i++ {
+		output, errorOutput := client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+		if errorOutput != nil {
+			t.Errorf("GetChannelByID errorOutput should have been nil: %+v", errorOutput)
+		}
+		if output.Status != "Playing" {
+			t.Errorf("GetChannelByID the status was not Playing: %s", output.Status)
+		}
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("The cached response was not used: %d", httpmock.GetTotalCallCount())
+	}
+
+	now = now.Add(2 * time.Minute)
+	client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+	if httpmock.GetTotalCallCount() != 2 {
+		t.Errorf("The expired response was used: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestCacheTokenIdentity(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234/editors",
+		httpmock.NewStringResponder(200, `{"users":[]}`))
+
+	cache := NewMemoryCache(10)
+	first := NewClient(&OAuthConfig{AccessToken: "first-token"}, &http.Client{}, WithCache(cache, time.Minute))
+	second := NewClient(&OAuthConfig{AccessToken: "second-token"}, &http.Client{}, WithCache(cache, time.Minute))
+
+	first.GetChannelEditors(&GetChannelEditorsInput{ChannelID: 1234})
+	second.GetChannelEditors(&GetChannelEditorsInput{ChannelID: 1234})
+	first.GetChannelEditors(&GetChannelEditorsInput{ChannelID: 1234})
+
+	if httpmock.GetTotalCallCount() != 2 {
+		t.Errorf("The responses were shared between tokens: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	ifNoneMatch := []string{}
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234/teams",
+		func(req *http.Request) (*http.Response, error) {
+			ifNoneMatch = append(ifNoneMatch, req.Header.Get("If-None-Match"))
+			if req.Header.Get("If-None-Match") == `"v1"` {
+				return httpmock.NewStringResponse(304, ""), nil
+			}
+			resp := httpmock.NewStringResponse(200, `{"teams":[{"_id":10,"name":"staff"}]}`)
+			resp.Header.Set("ETag", `"v1"`)
+			return resp, nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithCache(NewMemoryCache(10), 0))
+
+	client.GetChannelTeams(&GetChannelTeamsInput{ChannelID: 1234})
+	var metadata ResponseMetadata
+	output, errorOutput := client.GetChannelTeamsWithContext(WithResponseMetadata(context.Background(), &metadata), &GetChannelTeamsInput{ChannelID: 1234})
+
+	if errorOutput != nil {
+		t.Errorf("GetChannelTeams errorOutput should have been nil: %+v", errorOutput)
+	}
+	if len(output.Teams) != 1 || output.Teams[0].Name != "staff" {
+		t.Errorf("GetChannelTeams the cached teams were not returned: %+v", output.Teams)
+	}
+	if len(ifNoneMatch) != 2 || ifNoneMatch[0] != "" || ifNoneMatch[1] != `"v1"` {
+		t.Errorf("The response was not revalidated with its ETag: %v", ifNoneMatch)
+	}
+	if metadata.StatusCode != 304 {
+		t.Errorf("The status code was not 304: %d", metadata.StatusCode)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234"}`))
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/5678",
+		httpmock.NewStringResponder(200, `{"_id":"5678"}`))
+	httpmock.RegisterResponder("DELETE", "https://api.twitch.tv/kraken/channels/1234/stream_key",
+		httpmock.NewStringResponder(200, `{"_id":"1234","stream_key":"live_1234_new"}`))
+
+	cache := NewMemoryCache(10)
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithCache(cache, time.Minute))
+
+	client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+	client.GetChannelByID(&GetChannelByIDInput{ChannelID: 5678})
+	if cache.Len() != 2 {
+		t.Errorf("The responses were not cached: %d", cache.Len())
+	}
+
+	client.ResetStreamKey(&ResetStreamKeyInput{ChannelID: 1234})
+	if cache.Len() != 1 {
+		t.Errorf("Only the response for the reset channel should have been removed: %d", cache.Len())
+	}
+
+	client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+	info := httpmock.GetCallCountInfo()
+	if info["GET https://api.twitch.tv/kraken/channels/1234"] != 2 {
+		t.Errorf("The channel was not requested again after the reset: %v", info)
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", &CacheEntry{Body: []byte("a")})
+	cache.Set("b", &CacheEntry{Body: []byte("b")})
+	cache.Get("a")
+	cache.Set("c", &CacheEntry{Body: []byte("c")})
+
+	if cache.Get("b") != nil {
+		t.Error("MemoryCache the least recently used entry was not removed")
+	}
+	if cache.Get("a") == nil || cache.Get("c") == nil {
+		t.Error("MemoryCache the recently used entries were removed")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("MemoryCache the size was not 2: %d", cache.Len())
+	}
+}
+
+func TestCacheAuthenticatedChannel(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	streamKey := "live_1234_old"
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewStringResponse(200, `{"_id":"1234","stream_key":"`+streamKey+`"}`), nil
+		})
+	httpmock.RegisterResponder("DELETE", "https://api.twitch.tv/kraken/channels/1234/stream_key",
+		func(req *http.Request) (*http.Response, error) {
+			streamKey = "live_1234_new"
+			return httpmock.NewStringResponse(200, `{"_id":"1234","stream_key":"`+streamKey+`"}`), nil
+		})
+
+	cache := NewMemoryCache(10)
+	client := NewClient(&OAuthConfig{AccessToken: "access-token"}, &http.Client{}, WithCache(cache, time.Minute))
+
+	client.GetChannel()
+	client.ResetStreamKey(&ResetStreamKeyInput{ChannelID: 1234})
+	output, errorOutput := client.GetChannel()
+
+	if errorOutput != nil {
+		t.Errorf("GetChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.StreamKey != "live_1234_new" {
+		t.Errorf("GetChannel the stale stream key was returned: %s", output.StreamKey)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("The authenticated channel should not have been cached: %d", cache.Len())
+	}
+}
+
+func TestCacheValidateToken(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	valid := true
+	httpmock.RegisterResponder("GET", "https://id.twitch.tv/oauth2/validate",
+		func(req *http.Request) (*http.Response, error) {
+			if !valid {
+				return httpmock.NewStringResponse(401, `{"status":401,"message":"invalid access token"}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"client_id":"client-id","login":"dallas","user_id":"1234"}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithCache(NewMemoryCache(10), time.Hour))
+
+	client.ValidateToken(&ValidateTokenInput{AccessToken: "access-token"})
+	valid = false
+	_, errorOutput := client.ValidateToken(&ValidateTokenInput{AccessToken: "access-token"})
+
+	if errorOutput == nil {
+		t.Error("ValidateToken the revoked token was validated from the cache")
+	}
+	if httpmock.GetTotalCallCount() != 2 {
+		t.Errorf("ValidateToken the token should have been validated by Twitch both times: %d", httpmock.GetTotalCallCount())
+	}
+}
@@ -1,17 +1,23 @@
 package twitch
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// ErrChannelFieldReadOnly ModifyChannel changed a field of the channel that can't be updated, the change would be lost
+var ErrChannelFieldReadOnly = errors.New("twitch: ModifyChannel can only change the status, game, delay and channel feed")
+
 //GetChannelByIDInput the inputs used with the get channel by id endpoint
 type GetChannelByIDInput struct {
 	ChannelID int64
 }
 
-//UpdateChannelInput the inputs used with the update channel endpoint
+//UpdateChannelInput the inputs used with the update channel endpoint, every field is sent
 type UpdateChannelInput struct {
 	ChannelID          int64
 	Status             string
@@ -20,6 +26,15 @@ type UpdateChannelInput struct {
 	ChannelFeedEnabled bool
 }
 
+//UpdateChannelFieldsInput the inputs used to update some of the fields of a channel, only the fields that are set are changed
+type UpdateChannelFieldsInput struct {
+	ChannelID          int64
+	Status             *string
+	Game               *string
+	Delay              *int64
+	ChannelFeedEnabled *bool
+}
+
 //GetChannelEditorsInput the inputs used with the get channel editors endpoint
 type GetChannelEditorsInput struct {
 	ChannelID int64
@@ -120,43 +135,149 @@ type ResetStreamKeyInput struct {
 
 // GetChannel - the channel details for the authenticated user
 func (c *Client) GetChannel() (*Channel, *ErrorOutput) {
+	return c.GetChannelWithContext(context.Background())
+}
+
+// GetChannelWithContext - the same as GetChannel but the request is bound to the context
+func (c *Client) GetChannelWithContext(ctx context.Context) (*Channel, *ErrorOutput) {
 	output := new(Channel)
-	errorOutput := c.sendAPIRequest("GET", "channel", nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetChannel", "GET", "channel", nil, output)
 	return output, errorOutput
 }
 
 // GetChannelByID - Get a single channel feed post
 func (c *Client) GetChannelByID(input *GetChannelByIDInput) (*Channel, *ErrorOutput) {
+	return c.GetChannelByIDWithContext(context.Background(), input)
+}
+
+// GetChannelByIDWithContext - the same as GetChannelByID but the request is bound to the context
+func (c *Client) GetChannelByIDWithContext(ctx context.Context, input *GetChannelByIDInput) (*Channel, *ErrorOutput) {
 	output := new(Channel)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("channels/%d", input.ChannelID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetChannelByID", "GET", fmt.Sprintf("channels/%d", input.ChannelID), nil, output)
 	return output, errorOutput
 }
 
-// UpdateChannel - Updates a channel metadata
+// UpdateChannel - Updates a channel metadata, every field is sent so use UpdateChannelFields to change only some of them
 func (c *Client) UpdateChannel(input *UpdateChannelInput) (*Channel, *ErrorOutput) {
+	return c.UpdateChannelWithContext(context.Background(), input)
+}
+
+// UpdateChannelWithContext - the same as UpdateChannel but the request is bound to the context
+func (c *Client) UpdateChannelWithContext(ctx context.Context, input *UpdateChannelInput) (*Channel, *ErrorOutput) {
 	params := map[string]string{
 		"status":               input.Status,
 		"game":                 input.Game,
 		"delay":                fmt.Sprintf("%d", input.Delay),
-		"channel_feed_enabled": "true",
+		"channel_feed_enabled": strconv.FormatBool(input.ChannelFeedEnabled),
+	}
+	output := new(Channel)
+	errorOutput := c.sendAPIRequest(ctx, "UpdateChannel", "PUT", fmt.Sprintf("channel/%d", input.ChannelID), params, output)
+	return output, errorOutput
+}
+
+// UpdateChannelFields - Updates only the fields of the channel metadata that are set
+func (c *Client) UpdateChannelFields(input *UpdateChannelFieldsInput) (*Channel, *ErrorOutput) {
+	return c.UpdateChannelFieldsWithContext(context.Background(), input)
+}
+
+// UpdateChannelFieldsWithContext - the same as UpdateChannelFields but the request is bound to the context
+func (c *Client) UpdateChannelFieldsWithContext(ctx context.Context, input *UpdateChannelFieldsInput) (*Channel, *ErrorOutput) {
+	params := map[string]string{}
+	if input.Status != nil {
+		params["status"] = *input.Status
 	}
-	if input.ChannelFeedEnabled == false {
-		params["channel_feed_enabled"] = "false"
+	if input.Game != nil {
+		params["game"] = *input.Game
+	}
+	if input.Delay != nil {
+		params["delay"] = fmt.Sprintf("%d", *input.Delay)
+	}
+	if input.ChannelFeedEnabled != nil {
+		params["channel_feed_enabled"] = strconv.FormatBool(*input.ChannelFeedEnabled)
 	}
 	output := new(Channel)
-	errorOutput := c.sendAPIRequest("PUT", fmt.Sprintf("channel/%d", input.ChannelID), params, output)
+	errorOutput := c.sendAPIRequest(ctx, "UpdateChannelFields", "PUT", fmt.Sprintf("channel/%d", input.ChannelID), params, output)
 	return output, errorOutput
 }
 
+// ModifyChannel - Get a channel, change it with modify and update only the fields that were changed.
+// The status, game, delay and channel feed can be changed, ErrChannelFieldReadOnly is returned when another field was.
+func (c *Client) ModifyChannel(channelID int64, modify func(channel *Channel)) (*Channel, *ErrorOutput) {
+	return c.ModifyChannelWithContext(context.Background(), channelID, modify)
+}
+
+// ModifyChannelWithContext - the same as ModifyChannel but the requests are bound to the context
+func (c *Client) ModifyChannelWithContext(ctx context.Context, channelID int64, modify func(channel *Channel)) (*Channel, *ErrorOutput) {
+	ctx, span := c.startSpan(ctx, "ModifyChannel", nil)
+	channel, errorOutput := c.modifyChannel(ctx, channelID, modify)
+	endSpan(span, nil, errorOutput.Err())
+	return channel, errorOutput
+}
+
+//modifyChannel get the channel and update the fields modify changed
+func (c *Client) modifyChannel(ctx context.Context, channelID int64, modify func(channel *Channel)) (*Channel, *ErrorOutput) {
+	// A cached channel may be out of date, and the update is worked out from it
+	current, errorOutput := c.GetChannelByIDWithContext(withoutCache(ctx), &GetChannelByIDInput{ChannelID: channelID})
+	if errorOutput != nil {
+		return nil, errorOutput
+	}
+
+	modified := *current
+	modify(&modified)
+
+	if readOnlyChannelFields(modified) != readOnlyChannelFields(*current) {
+		return nil, &ErrorOutput{
+			Message: ErrChannelFieldReadOnly.Error(),
+			Error:   "Twitchy error",
+			Status:  -1,
+			err:     ErrChannelFieldReadOnly,
+		}
+	}
+
+	input := &UpdateChannelFieldsInput{ChannelID: channelID}
+	if modified.Status != current.Status {
+		input.Status = String(modified.Status)
+	}
+	if modified.Game != current.Game {
+		input.Game = String(modified.Game)
+	}
+	if modified.Delay != current.Delay {
+		input.Delay = Int64(modified.Delay)
+	}
+	if modified.ChannelFeedEnabled != current.ChannelFeedEnabled {
+		input.ChannelFeedEnabled = Bool(modified.ChannelFeedEnabled)
+	}
+	if *input == (UpdateChannelFieldsInput{ChannelID: channelID}) {
+		return current, nil
+	}
+	return c.UpdateChannelFieldsWithContext(ctx, input)
+}
+
+//readOnlyChannelFields the channel with the fields UpdateChannelFields can write cleared
+func readOnlyChannelFields(channel Channel) Channel {
+	channel.Status, channel.Game, channel.Delay, channel.ChannelFeedEnabled = "", "", 0, false
+	return channel
+}
+
 // GetChannelEditors - Get a the editors for a channel
 func (c *Client) GetChannelEditors(input *GetChannelEditorsInput) (*GetChannelEditorsOutput, *ErrorOutput) {
+	return c.GetChannelEditorsWithContext(context.Background(), input)
+}
+
+// GetChannelEditorsWithContext - the same as GetChannelEditors but the request is bound to the context
+func (c *Client) GetChannelEditorsWithContext(ctx context.Context, input *GetChannelEditorsInput) (*GetChannelEditorsOutput, *ErrorOutput) {
 	output := new(GetChannelEditorsOutput)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("channels/%d/editors", input.ChannelID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetChannelEditors", "GET", fmt.Sprintf("channels/%d/editors", input.ChannelID), nil, output)
 	return output, errorOutput
 }
 
 // GetChannelFollowers - Get a the followers for a channel
 func (c *Client) GetChannelFollowers(input *GetChannelFollowersInput) (*GetChannelFollowersOutput, *ErrorOutput) {
+	return c.GetChannelFollowersWithContext(context.Background(), input)
+}
+
+// GetChannelFollowersWithContext - the same as GetChannelFollowers but the request is bound to the context
+func (c *Client) GetChannelFollowersWithContext(ctx context.Context, input *GetChannelFollowersInput) (*GetChannelFollowersOutput, *ErrorOutput) {
 	params := map[string]string{
 		"limit":     fmt.Sprintf("%d", input.Limit),
 		"offset":    fmt.Sprintf("%d", input.Offset),
@@ -164,38 +285,106 @@ func (c *Client) GetChannelFollowers(input *GetChannelFollowersInput) (*GetChann
 		"direction": input.Direction,
 	}
 	output := new(GetChannelFollowersOutput)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("channels/%d/follows", input.ChannelID), params, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetChannelFollowers", "GET", fmt.Sprintf("channels/%d/follows", input.ChannelID), params, output)
 	return output, errorOutput
 }
 
+// GetChannelFollowersPages - walk every page of followers for a channel using the cursor, fn returns false to stop early
+func (c *Client) GetChannelFollowersPages(input *GetChannelFollowersInput, fn func(page *GetChannelFollowersOutput, lastPage bool) bool) *ErrorOutput {
+	return c.GetChannelFollowersPagesWithContext(context.Background(), input, fn)
+}
+
+// GetChannelFollowersPagesWithContext - the same as GetChannelFollowersPages but the requests are bound to the context
+func (c *Client) GetChannelFollowersPagesWithContext(ctx context.Context, input *GetChannelFollowersInput, fn func(page *GetChannelFollowersOutput, lastPage bool) bool) *ErrorOutput {
+	return c.traceCalls(ctx, "GetChannelFollowersPages", func(ctx context.Context) *ErrorOutput {
+		pageInput := *input
+		pageInput.Limit = pageLimit(input.Limit)
+		for {
+			output, errorOutput := c.GetChannelFollowersWithContext(ctx, &pageInput)
+			if errorOutput != nil {
+				return errorOutput
+			}
+			pageInput.Cursor = output.Cursor
+			lastPage := output.Cursor == "" || len(output.Follows) == 0
+			if !fn(output, lastPage) || lastPage {
+				return nil
+			}
+		}
+	})
+}
+
 // GetChannelTeams - Get a the editors for a channel
 func (c *Client) GetChannelTeams(input *GetChannelTeamsInput) (*GetChannelTeamsOutput, *ErrorOutput) {
+	return c.GetChannelTeamsWithContext(context.Background(), input)
+}
+
+// GetChannelTeamsWithContext - the same as GetChannelTeams but the request is bound to the context
+func (c *Client) GetChannelTeamsWithContext(ctx context.Context, input *GetChannelTeamsInput) (*GetChannelTeamsOutput, *ErrorOutput) {
 	output := new(GetChannelTeamsOutput)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("channels/%d/teams", input.ChannelID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetChannelTeams", "GET", fmt.Sprintf("channels/%d/teams", input.ChannelID), nil, output)
 	return output, errorOutput
 }
 
 // GetChannelSubscribers - Get a the subscribers for a channel
 func (c *Client) GetChannelSubscribers(input *GetChannelSubscribersInput) (*GetChannelSubscribersOutput, *ErrorOutput) {
+	return c.GetChannelSubscribersWithContext(context.Background(), input)
+}
+
+// GetChannelSubscribersWithContext - the same as GetChannelSubscribers but the request is bound to the context
+func (c *Client) GetChannelSubscribersWithContext(ctx context.Context, input *GetChannelSubscribersInput) (*GetChannelSubscribersOutput, *ErrorOutput) {
 	params := map[string]string{
 		"limit":     fmt.Sprintf("%d", input.Limit),
 		"offset":    fmt.Sprintf("%d", input.Offset),
 		"direction": input.Direction,
 	}
 	output := new(GetChannelSubscribersOutput)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("channels/%d/subscriptions", input.ChannelID), params, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetChannelSubscribers", "GET", fmt.Sprintf("channels/%d/subscriptions", input.ChannelID), params, output)
 	return output, errorOutput
 }
 
+// GetChannelSubscribersPages - walk every page of subscribers for a channel, fn returns false to stop early
+func (c *Client) GetChannelSubscribersPages(input *GetChannelSubscribersInput, fn func(page *GetChannelSubscribersOutput, lastPage bool) bool) *ErrorOutput {
+	return c.GetChannelSubscribersPagesWithContext(context.Background(), input, fn)
+}
+
+// GetChannelSubscribersPagesWithContext - the same as GetChannelSubscribersPages but the requests are bound to the context
+func (c *Client) GetChannelSubscribersPagesWithContext(ctx context.Context, input *GetChannelSubscribersInput, fn func(page *GetChannelSubscribersOutput, lastPage bool) bool) *ErrorOutput {
+	return c.traceCalls(ctx, "GetChannelSubscribersPages", func(ctx context.Context) *ErrorOutput {
+		pageInput := *input
+		pageInput.Limit = pageLimit(input.Limit)
+		for {
+			output, errorOutput := c.GetChannelSubscribersWithContext(ctx, &pageInput)
+			if errorOutput != nil {
+				return errorOutput
+			}
+			pageInput.Offset += int64(len(output.Subscriptions))
+			lastPage := lastOffsetPage(len(output.Subscriptions), pageInput.Limit, pageInput.Offset, output.Total)
+			if !fn(output, lastPage) || lastPage {
+				return nil
+			}
+		}
+	})
+}
+
 // CheckChannelSubscriptionByUser - Get a single subscription by user ID
 func (c *Client) CheckChannelSubscriptionByUser(input *CheckChannelSubscriptionByUserInput) (*Subscription, *ErrorOutput) {
+	return c.CheckChannelSubscriptionByUserWithContext(context.Background(), input)
+}
+
+// CheckChannelSubscriptionByUserWithContext - the same as CheckChannelSubscriptionByUser but the request is bound to the context
+func (c *Client) CheckChannelSubscriptionByUserWithContext(ctx context.Context, input *CheckChannelSubscriptionByUserInput) (*Subscription, *ErrorOutput) {
 	output := new(Subscription)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("channels/%d/subscriptions/%d", input.ChannelID, input.UserID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "CheckChannelSubscriptionByUser", "GET", fmt.Sprintf("channels/%d/subscriptions/%d", input.ChannelID, input.UserID), nil, output)
 	return output, errorOutput
 }
 
 // GetChannelVideos - Get a the videos for a channel
 func (c *Client) GetChannelVideos(input *GetChannelVideosInput) (*GetChannelVideosOutput, *ErrorOutput) {
+	return c.GetChannelVideosWithContext(context.Background(), input)
+}
+
+// GetChannelVideosWithContext - the same as GetChannelVideos but the request is bound to the context
+func (c *Client) GetChannelVideosWithContext(ctx context.Context, input *GetChannelVideosInput) (*GetChannelVideosOutput, *ErrorOutput) {
 	params := map[string]string{
 		"limit":          fmt.Sprintf("%d", input.Limit),
 		"offset":         fmt.Sprintf("%d", input.Offset),
@@ -204,23 +393,57 @@ func (c *Client) GetChannelVideos(input *GetChannelVideosInput) (*GetChannelVide
 		"sort":           input.Sort,
 	}
 	output := new(GetChannelVideosOutput)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("channels/%d/videos", input.ChannelID), params, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetChannelVideos", "GET", fmt.Sprintf("channels/%d/videos", input.ChannelID), params, output)
 	return output, errorOutput
 }
 
+// GetChannelVideosPages - walk every page of videos for a channel, fn returns false to stop early
+func (c *Client) GetChannelVideosPages(input *GetChannelVideosInput, fn func(page *GetChannelVideosOutput, lastPage bool) bool) *ErrorOutput {
+	return c.GetChannelVideosPagesWithContext(context.Background(), input, fn)
+}
+
+// GetChannelVideosPagesWithContext - the same as GetChannelVideosPages but the requests are bound to the context
+func (c *Client) GetChannelVideosPagesWithContext(ctx context.Context, input *GetChannelVideosInput, fn func(page *GetChannelVideosOutput, lastPage bool) bool) *ErrorOutput {
+	return c.traceCalls(ctx, "GetChannelVideosPages", func(ctx context.Context) *ErrorOutput {
+		pageInput := *input
+		pageInput.Limit = pageLimit(input.Limit)
+		for {
+			output, errorOutput := c.GetChannelVideosWithContext(ctx, &pageInput)
+			if errorOutput != nil {
+				return errorOutput
+			}
+			pageInput.Offset += int64(len(output.Videos))
+			lastPage := lastOffsetPage(len(output.Videos), pageInput.Limit, pageInput.Offset, output.Total)
+			if !fn(output, lastPage) || lastPage {
+				return nil
+			}
+		}
+	})
+}
+
 // StartChannelCommercial - Start a commercial for a channel
 func (c *Client) StartChannelCommercial(input *StartChannelCommercialInput) (*StartChannelCommercialOutput, *ErrorOutput) {
+	return c.StartChannelCommercialWithContext(context.Background(), input)
+}
+
+// StartChannelCommercialWithContext - the same as StartChannelCommercial but the request is bound to the context
+func (c *Client) StartChannelCommercialWithContext(ctx context.Context, input *StartChannelCommercialInput) (*StartChannelCommercialOutput, *ErrorOutput) {
 	params := map[string]string{
 		"length": fmt.Sprintf("%d", input.Length),
 	}
 	output := new(StartChannelCommercialOutput)
-	errorOutput := c.sendAPIRequest("POST", fmt.Sprintf("channel/%d/commercial", input.ChannelID), params, output)
+	errorOutput := c.sendAPIRequest(ctx, "StartChannelCommercial", "POST", fmt.Sprintf("channel/%d/commercial", input.ChannelID), params, output)
 	return output, errorOutput
 }
 
 // ResetStreamKey - Reset the stream key for a channel
 func (c *Client) ResetStreamKey(input *ResetStreamKeyInput) (*Channel, *ErrorOutput) {
+	return c.ResetStreamKeyWithContext(context.Background(), input)
+}
+
+// ResetStreamKeyWithContext - the same as ResetStreamKey but the request is bound to the context
+func (c *Client) ResetStreamKeyWithContext(ctx context.Context, input *ResetStreamKeyInput) (*Channel, *ErrorOutput) {
 	output := new(Channel)
-	errorOutput := c.sendAPIRequest("DELETE", fmt.Sprintf("channels/%d/stream_key", input.ChannelID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "ResetStreamKey", "DELETE", fmt.Sprintf("channels/%d/stream_key", input.ChannelID), nil, output)
 	return output, errorOutput
 }
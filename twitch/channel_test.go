@@ -1,8 +1,11 @@
 package twitch
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 )
@@ -359,3 +362,271 @@ func TestResetStreamKey(t *testing.T) {
 		t.Errorf("ResetStreamKey the name was not dallas: %s", output.Name)
 	}
 }
+
+func TestGetChannelFollowersPages(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	cursors := []string{}
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234/follows",
+		func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			cursors = append(cursors, query.Get("cursor"))
+			if query.Get("limit") != "100" {
+				t.Errorf("GetChannelFollowersPages the limit was not capped at 100: %s", query.Get("limit"))
+			}
+			if query.Get("cursor") == "" {
+				return httpmock.NewStringResponse(200, `{"_cursor":"next","_total":2,"follows":[{"user":{"_id":1}}]}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"_cursor":"","_total":2,"follows":[{"user":{"_id":2}}]}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	ids := []int64{}
+	errorOutput := client.GetChannelFollowersPages(&GetChannelFollowersInput{ChannelID: 1234, Limit: 500}, func(page *GetChannelFollowersOutput, lastPage bool) bool {
+		for _, follow := range page.Follows {
+			ids = append(ids, follow.User.ID)
+		}
+		return true
+	})
+
+	if errorOutput != nil {
+		t.Errorf("GetChannelFollowersPages errorOutput should have been nil: %+v", errorOutput)
+	}
+	if len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("GetChannelFollowersPages the follows of every page were not walked: %v", ids)
+	}
+	if len(cursors) != 2 || cursors[1] != "next" {
+		t.Errorf("GetChannelFollowersPages the cursor of the first page was not sent: %v", cursors)
+	}
+}
+
+func TestGetChannelVideosPagesStop(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234/videos",
+		httpmock.NewStringResponder(200, `{"_total":583,"videos":[{"_id":"v1"}]}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	pages := 0
+	errorOutput := client.GetChannelVideosPages(&GetChannelVideosInput{ChannelID: 1234, Limit: 1}, func(page *GetChannelVideosOutput, lastPage bool) bool {
+		pages++
+		return pages < 2
+	})
+
+	if errorOutput != nil {
+		t.Errorf("GetChannelVideosPages errorOutput should have been nil: %+v", errorOutput)
+	}
+	if pages != 2 {
+		t.Errorf("GetChannelVideosPages did not stop when fn returned false: %d", pages)
+	}
+	if httpmock.GetTotalCallCount() != 2 {
+		t.Errorf("GetChannelVideosPages the number of requests was not 2: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestGetChannelSubscribersPagesError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234/subscriptions",
+		httpmock.NewStringResponder(404, `{"error":"Not Found","status":404,"message":"Channel not found"}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	pages := 0
+	errorOutput := client.GetChannelSubscribersPages(&GetChannelSubscribersInput{ChannelID: 1234}, func(page *GetChannelSubscribersOutput, lastPage bool) bool {
+		pages++
+		return true
+	})
+
+	if errorOutput == nil || errorOutput.Status != 404 {
+		t.Errorf("GetChannelSubscribersPages the 404 error was not returned: %+v", errorOutput)
+	}
+	if pages != 0 {
+		t.Errorf("GetChannelSubscribersPages fn should not have been called: %d", pages)
+	}
+}
+
+func TestUpdateChannelFields(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var form url.Values
+	httpmock.RegisterResponder("PUT", "https://api.twitch.tv/kraken/channel/1234",
+		func(req *http.Request) (*http.Response, error) {
+			form = requestForm(req)
+			return httpmock.NewStringResponse(200, `{"_id":"1234","status":"New title","game":"Nioh"}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	_, errorOutput := client.UpdateChannelFields(&UpdateChannelFieldsInput{
+		ChannelID: 1234,
+		Status:    String("New title"),
+		Delay:     Int64(0),
+	})
+
+	if errorOutput != nil {
+		t.Errorf("UpdateChannelFields errorOutput should have been nil: %+v", errorOutput)
+	}
+	if form.Get("status") != "New title" {
+		t.Errorf("UpdateChannelFields the status was not sent: %v", form)
+	}
+	if form.Get("delay") != "0" {
+		t.Errorf("UpdateChannelFields the delay of 0 was not sent: %v", form)
+	}
+	if _, ok := form["game"]; ok {
+		t.Errorf("UpdateChannelFields the game should not have been sent: %v", form)
+	}
+	if _, ok := form["channel_feed_enabled"]; ok {
+		t.Errorf("UpdateChannelFields the channel feed enabled should not have been sent: %v", form)
+	}
+}
+
+func TestModifyChannel(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"Old title","game":"Nioh"}`))
+	var form url.Values
+	httpmock.RegisterResponder("PUT", "https://api.twitch.tv/kraken/channel/1234",
+		func(req *http.Request) (*http.Response, error) {
+			form = requestForm(req)
+			return httpmock.NewStringResponse(200, `{"_id":"1234","status":"New title","game":"Nioh"}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	output, errorOutput := client.ModifyChannel(1234, func(channel *Channel) {
+		channel.Status = "New title"
+	})
+
+	if errorOutput != nil {
+		t.Errorf("ModifyChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.Status != "New title" {
+		t.Errorf("ModifyChannel the updated channel was not returned: %s", output.Status)
+	}
+	if len(form) != 1 || form.Get("status") != "New title" {
+		t.Errorf("ModifyChannel only the status should have been sent: %v", form)
+	}
+}
+
+func TestModifyChannelUnchanged(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"Old title","game":"Nioh"}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	output, errorOutput := client.ModifyChannel(1234, func(channel *Channel) {
+		channel.Game = "Nioh"
+	})
+
+	if errorOutput != nil {
+		t.Errorf("ModifyChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.Status != "Old title" {
+		t.Errorf("ModifyChannel the current channel was not returned: %s", output.Status)
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("ModifyChannel the channel should not have been updated: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestModifyChannelDelayAndFeed(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"Old title","game":"Nioh","delay":0,"channel_feed_enabled":true}`))
+	var form url.Values
+	httpmock.RegisterResponder("PUT", "https://api.twitch.tv/kraken/channel/1234",
+		func(req *http.Request) (*http.Response, error) {
+			form = requestForm(req)
+			return httpmock.NewStringResponse(200, `{"_id":"1234","status":"Old title","game":"Nioh","delay":30,"channel_feed_enabled":false}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	output, errorOutput := client.ModifyChannel(1234, func(channel *Channel) {
+		channel.Delay = 30
+		channel.ChannelFeedEnabled = false
+	})
+
+	if errorOutput != nil {
+		t.Errorf("ModifyChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.Delay != 30 || output.ChannelFeedEnabled {
+		t.Errorf("ModifyChannel the updated channel was not returned: %+v", output)
+	}
+	if len(form) != 2 || form.Get("delay") != "30" || form.Get("channel_feed_enabled") != "false" {
+		t.Errorf("ModifyChannel only the delay and channel feed should have been sent: %v", form)
+	}
+}
+
+func TestModifyChannelReadOnly(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"Old title","broadcaster_language":"en"}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	output, errorOutput := client.ModifyChannel(1234, func(channel *Channel) {
+		channel.Status = "New title"
+		channel.BroadcasterLanguage = "fr"
+	})
+
+	if !errors.Is(errorOutput.Err(), ErrChannelFieldReadOnly) {
+		t.Errorf("ModifyChannel error was not ErrChannelFieldReadOnly: %v", errorOutput.Err())
+	}
+	if output != nil {
+		t.Errorf("ModifyChannel no channel should have been returned: %+v", output)
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("ModifyChannel the channel should not have been updated: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestModifyChannelCached(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	status := "Old title"
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewStringResponse(200, `{"_id":"1234","status":"`+status+`","game":"Nioh"}`), nil
+		})
+	var form url.Values
+	httpmock.RegisterResponder("PUT", "https://api.twitch.tv/kraken/channel/1234",
+		func(req *http.Request) (*http.Response, error) {
+			form = requestForm(req)
+			return httpmock.NewStringResponse(200, `{"_id":"1234","status":"Old title","game":"Nioh"}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithCache(NewMemoryCache(10), time.Hour))
+
+	client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+	// The title is changed somewhere else while the old one is cached
+	status = "New title"
+	client.ModifyChannel(1234, func(channel *Channel) {
+		channel.Status = "Old title"
+	})
+
+	if form.Get("status") != "Old title" {
+		t.Errorf("ModifyChannel the change was worked out from the cached channel: %v", form)
+	}
+	info := httpmock.GetCallCountInfo()
+	if info["GET https://api.twitch.tv/kraken/channels/1234"] != 2 {
+		t.Errorf("ModifyChannel the channel should have been read from Twitch: %d", info["GET https://api.twitch.tv/kraken/channels/1234"])
+	}
+}
@@ -0,0 +1,85 @@
+package twitch
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+//GetAppAccessTokenInput the inputs used to get an app access token with the client credentials grant
+type GetAppAccessTokenInput struct {
+	Scopes []string
+}
+
+// GetAppAccessToken - Get an app access token using the client ID and secret, no user is involved
+func (c *Client) GetAppAccessToken(input *GetAppAccessTokenInput) (*Token, *ErrorOutput) {
+	return c.GetAppAccessTokenWithContext(context.Background(), input)
+}
+
+// GetAppAccessTokenWithContext - the same as GetAppAccessToken but the request is bound to the context
+func (c *Client) GetAppAccessTokenWithContext(ctx context.Context, input *GetAppAccessTokenInput) (*Token, *ErrorOutput) {
+	params := map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     c.oauthConfig.ClientID,
+		"client_secret": c.oauthConfig.ClientSecret,
+	}
+	if len(input.Scopes) > 0 {
+		params["scope"] = strings.Join(input.Scopes, " ")
+	}
+	return c.requestToken(withEndpoint(ctx, "GetAppAccessToken"), params)
+}
+
+//ClientCredentialsTokenSource a token source for app access tokens, acquired again when they expire or are rejected
+type ClientCredentialsTokenSource struct {
+	client *Client
+	scopes []string
+	mu     sync.Mutex
+	token  *Token
+}
+
+// NewClientCredentialsTokenSource - create a token source using the client ID and secret of the client's OAuthConfig
+func (c *Client) NewClientCredentialsTokenSource(scopes ...string) *ClientCredentialsTokenSource {
+	return &ClientCredentialsTokenSource{
+		client: c,
+		scopes: scopes,
+	}
+}
+
+//WithAppAccessToken authorize requests with app access tokens from the client credentials grant
+func WithAppAccessToken(scopes ...string) ClientOption {
+	return func(c *Client) {
+		c.tokenSource = c.NewClientCredentialsTokenSource(scopes...)
+	}
+}
+
+// Token - the cached app access token, a new one is acquired when it is missing or about to expire
+func (s *ClientCredentialsTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil || (!s.token.Expiry.IsZero() && s.client.now().Add(tokenExpiryDelta).After(s.token.Expiry)) {
+		return s.acquire(ctx)
+	}
+	return s.token, nil
+}
+
+// Refresh - acquire a new app access token, unless another caller has already replaced the rejected one
+func (s *ClientCredentialsTokenSource) Refresh(ctx context.Context, rejected *Token) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && rejected != nil && s.token.AccessToken != rejected.AccessToken {
+		return s.token, nil
+	}
+	return s.acquire(ctx)
+}
+
+//acquire get a new app access token, the lock must be held
+func (s *ClientCredentialsTokenSource) acquire(ctx context.Context) (*Token, error) {
+	token, errorOutput := s.client.GetAppAccessTokenWithContext(ctx, &GetAppAccessTokenInput{Scopes: s.scopes})
+	if errorOutput != nil {
+		return nil, errorOutput.Err()
+	}
+	s.token = token
+	return token, nil
+}
@@ -0,0 +1,104 @@
+package twitch
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetAppAccessToken(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+
+	token, errorOutput := client.GetAppAccessToken(&GetAppAccessTokenInput{Scopes: []string{"channel_read", "user_read"}})
+
+	if errorOutput != nil {
+		t.Fatalf("GetAppAccessToken errorOutput should have been nil: %+v", errorOutput)
+	}
+	if token.AccessToken != "access-token-1" {
+		t.Errorf("GetAppAccessToken access token was not \"access-token-1\": %s", token.AccessToken)
+	}
+	form := fake.forms[0]
+	if form["grant_type"] != "client_credentials" || form["client_id"] != "client-id" || form["client_secret"] != "client-secret" || form["scope"] != "channel_read user_read" {
+		t.Errorf("GetAppAccessToken form was not correct: %+v", form)
+	}
+}
+
+func TestGetAppAccessTokenInvalidSecret(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+	client.oauthConfig.ClientSecret = "wrong"
+	client.SetTokenSource(client.NewClientCredentialsTokenSource())
+
+	_, errorOutput := client.GetChannel()
+
+	apiError := &APIError{}
+	if !errors.As(errorOutput.Err(), &apiError) || apiError.StatusCode != 400 {
+		t.Errorf("GetAppAccessTokenInvalidSecret error was not the token endpoint error: %v", errorOutput.Err())
+	}
+}
+
+func TestClientCredentialsTokenSourceCaching(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	now := time.Unix(1500000000, 0)
+	client := newFakeTwitchClient(server,
+		WithClock(func() time.Time { return now }),
+		WithAppAccessToken("channel_read"),
+	)
+
+	for i := 0; i < 3; i++ {
+		if _, errorOutput := client.GetChannel(); errorOutput != nil {
+			t.Fatalf("ClientCredentialsTokenSourceCaching errorOutput should have been nil: %+v", errorOutput)
+		}
+	}
+	if atomic.LoadInt32(&fake.refreshes) != 1 {
+		t.Errorf("ClientCredentialsTokenSourceCaching the token should have been acquired once: %d", fake.refreshes)
+	}
+
+	// Close to the expiry a new token is acquired
+	now = now.Add(time.Hour - 30*time.Second)
+	if _, errorOutput := client.GetChannel(); errorOutput != nil {
+		t.Fatalf("ClientCredentialsTokenSourceCaching errorOutput should have been nil: %+v", errorOutput)
+	}
+	if atomic.LoadInt32(&fake.refreshes) != 2 {
+		t.Errorf("ClientCredentialsTokenSourceCaching the token should have been acquired again: %d", fake.refreshes)
+	}
+}
+
+func TestClientCredentialsTokenSourceRejected(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server, WithAppAccessToken())
+
+	client.GetChannel()
+
+	// Twitch stops accepting the token before it expires
+	fake.mu.Lock()
+	fake.accessToken = "revoked"
+	fake.mu.Unlock()
+
+	output, errorOutput := client.GetChannel()
+
+	if errorOutput != nil {
+		t.Fatalf("ClientCredentialsTokenSourceRejected errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.ID != "1234" {
+		t.Errorf("ClientCredentialsTokenSourceRejected the ID was not 1234: %s", output.ID)
+	}
+	if atomic.LoadInt32(&fake.refreshes) != 2 {
+		t.Errorf("ClientCredentialsTokenSourceRejected the token should have been acquired again: %d", fake.refreshes)
+	}
+}
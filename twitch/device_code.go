@@ -0,0 +1,136 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// deviceCodeGrantType the grant type used to poll for the token of a device code
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// ErrDeviceCodeExpired the user didn't authorize the device before the device code expired
+var ErrDeviceCodeExpired = errors.New("twitch: device code expired")
+
+//RequestDeviceCodeInput the inputs used to start the device authorization flow
+type RequestDeviceCodeInput struct {
+	Scopes []string
+}
+
+//DeviceCode the code the user enters at the verification URI to authorize the device
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Interval        int64  `json:"interval"`
+}
+
+//PollDeviceTokenInput the inputs used to check whether the user has authorized the device
+type PollDeviceTokenInput struct {
+	DeviceCode string
+	Scopes     []string
+}
+
+// RequestDeviceCode - Start the device authorization flow for a tool without a browser
+func (c *Client) RequestDeviceCode(input *RequestDeviceCodeInput) (*DeviceCode, *ErrorOutput) {
+	return c.RequestDeviceCodeWithContext(context.Background(), input)
+}
+
+// RequestDeviceCodeWithContext - the same as RequestDeviceCode but the request is bound to the context
+func (c *Client) RequestDeviceCodeWithContext(ctx context.Context, input *RequestDeviceCodeInput) (*DeviceCode, *ErrorOutput) {
+	params := map[string]string{
+		"client_id": c.oauthConfig.ClientID,
+		"scopes":    strings.Join(input.Scopes, " "),
+	}
+	output := new(DeviceCode)
+	errorOutput := c.performRequest(withEndpoint(ctx, "RequestDeviceCode"), c.createOAuthRequest("POST", "device", params), output)
+	return output, errorOutput
+}
+
+// PollDeviceToken - Check once whether the user has authorized the device, Twitch answers authorization_pending until they have
+func (c *Client) PollDeviceToken(input *PollDeviceTokenInput) (*Token, *ErrorOutput) {
+	return c.PollDeviceTokenWithContext(context.Background(), input)
+}
+
+// PollDeviceTokenWithContext - the same as PollDeviceToken but the request is bound to the context
+func (c *Client) PollDeviceTokenWithContext(ctx context.Context, input *PollDeviceTokenInput) (*Token, *ErrorOutput) {
+	params := map[string]string{
+		"grant_type":  deviceCodeGrantType,
+		"device_code": input.DeviceCode,
+		"client_id":   c.oauthConfig.ClientID,
+		"scopes":      strings.Join(input.Scopes, " "),
+	}
+	return c.requestToken(withEndpoint(ctx, "PollDeviceToken"), params)
+}
+
+//DeviceFlow gets a user access token on a device without a browser, the user authorizes it on another device
+type DeviceFlow struct {
+	Scopes []string
+
+	client *Client
+	// sleep waits between polls, tests replace it so the interval passes without waiting
+	sleep func(ctx context.Context, delay time.Duration) error
+}
+
+// NewDeviceFlow - create a device authorization flow for the scopes
+func (c *Client) NewDeviceFlow(scopes []string) *DeviceFlow {
+	return &DeviceFlow{
+		Scopes: scopes,
+		client: c,
+		sleep:  sleepContext,
+	}
+}
+
+// Authorize - request a device code, show it to the user with prompt and wait until they have authorized the device
+func (f *DeviceFlow) Authorize(ctx context.Context, prompt func(*DeviceCode)) (*Token, error) {
+	code, errorOutput := f.client.RequestDeviceCodeWithContext(ctx, &RequestDeviceCodeInput{Scopes: f.Scopes})
+	if errorOutput != nil {
+		return nil, errorOutput.Err()
+	}
+	prompt(code)
+	return f.Wait(ctx, code)
+}
+
+// Wait - poll at the interval Twitch asked for until the user authorizes the device, the code expires or the context is done
+func (f *DeviceFlow) Wait(ctx context.Context, code *DeviceCode) (*Token, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expiry := f.client.now().Add(time.Duration(code.ExpiresIn) * time.Second)
+
+	for {
+		if err := f.sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+		if code.ExpiresIn > 0 && f.client.now().After(expiry) {
+			return nil, ErrDeviceCodeExpired
+		}
+
+		token, errorOutput := f.client.PollDeviceTokenWithContext(ctx, &PollDeviceTokenInput{
+			DeviceCode: code.DeviceCode,
+			Scopes:     f.Scopes,
+		})
+		if errorOutput == nil {
+			return token, nil
+		}
+
+		var apiError *APIError
+		if !errors.As(errorOutput.Err(), &apiError) {
+			return nil, errorOutput.Err()
+		}
+		switch apiError.Message {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return nil, &AuthorizationError{Code: apiError.Message}
+		case "expired_token", "invalid device code":
+			return nil, ErrDeviceCodeExpired
+		default:
+			return nil, apiError
+		}
+	}
+}
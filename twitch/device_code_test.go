@@ -0,0 +1,135 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//skippedTime a clock that only moves when the device flow sleeps, so the poll intervals pass without waiting
+type skippedTime struct {
+	now time.Time
+}
+
+func (s *skippedTime) clock() time.Time {
+	return s.now
+}
+
+func (s *skippedTime) sleep(ctx context.Context, delay time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.now = s.now.Add(delay)
+	return nil
+}
+
+//newSkippedTimeFlow a device flow on the fake Twitch that skips the time it would wait
+func newSkippedTimeFlow(server *httptest.Server, scopes []string) (*DeviceFlow, *skippedTime) {
+	skipped := &skippedTime{now: time.Now()}
+	flow := newFakeTwitchClient(server, WithClock(skipped.clock)).NewDeviceFlow(scopes)
+	flow.sleep = skipped.sleep
+	return flow, skipped
+}
+
+func TestRequestDeviceCode(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+
+	output, errorOutput := client.RequestDeviceCode(&RequestDeviceCodeInput{Scopes: []string{"channel_read", "user_read"}})
+
+	if errorOutput != nil {
+		t.Fatalf("RequestDeviceCode errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.DeviceCode != "device-code" || output.UserCode != "ABCDEFGH" || output.Interval != 1 || output.ExpiresIn != 1800 {
+		t.Errorf("RequestDeviceCode output was not correct: %+v", output)
+	}
+	if fake.forms[0]["client_id"] != "client-id" || fake.forms[0]["scopes"] != "channel_read user_read" {
+		t.Errorf("RequestDeviceCode form was not correct: %+v", fake.forms[0])
+	}
+}
+
+func TestDeviceFlowAuthorize(t *testing.T) {
+	fake := &fakeTwitch{devicePolls: []string{"authorization_pending", "slow_down", "authorization_pending"}}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	flow, skipped := newSkippedTimeFlow(server, []string{"channel_read"})
+
+	var prompted *DeviceCode
+	start := skipped.clock()
+	token, err := flow.Authorize(context.Background(), func(code *DeviceCode) {
+		prompted = code
+	})
+
+	if err != nil {
+		t.Fatalf("DeviceFlowAuthorize err should have been nil: %v", err)
+	}
+	if prompted == nil || prompted.UserCode != "ABCDEFGH" || prompted.VerificationURI == "" {
+		t.Errorf("DeviceFlowAuthorize the user was not prompted with the code: %+v", prompted)
+	}
+	if token.AccessToken != "access-token-1" {
+		t.Errorf("DeviceFlowAuthorize access token was not \"access-token-1\": %s", token.AccessToken)
+	}
+	// 4 polls at 1 second with 5 added after the slow down
+	if elapsed := skipped.clock().Sub(start); elapsed != 14*time.Second {
+		t.Errorf("DeviceFlowAuthorize did not slow down when asked to: %s", elapsed)
+	}
+	last := fake.forms[len(fake.forms)-1]
+	if last["grant_type"] != deviceCodeGrantType || last["device_code"] != "device-code" {
+		t.Errorf("DeviceFlowAuthorize poll form was not correct: %+v", last)
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	fake := &fakeTwitch{devicePolls: []string{"authorization_pending", "access_denied"}}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	flow, _ := newSkippedTimeFlow(server, nil)
+
+	_, err := flow.Authorize(context.Background(), func(code *DeviceCode) {})
+
+	authorizationError := &AuthorizationError{}
+	if !errors.As(err, &authorizationError) || authorizationError.Code != "access_denied" {
+		t.Errorf("DeviceFlowDenied error was not access_denied: %v", err)
+	}
+}
+
+func TestDeviceFlowExpired(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	flow, _ := newSkippedTimeFlow(server, nil)
+
+	_, err := flow.Wait(context.Background(), &DeviceCode{DeviceCode: "unknown", Interval: 1, ExpiresIn: 1800})
+	if err != ErrDeviceCodeExpired {
+		t.Errorf("DeviceFlowExpired invalid device code error was not ErrDeviceCodeExpired: %v", err)
+	}
+
+	_, err = flow.Wait(context.Background(), &DeviceCode{DeviceCode: "device-code", Interval: 2, ExpiresIn: 1})
+	if err != ErrDeviceCodeExpired {
+		t.Errorf("DeviceFlowExpired expired device code error was not ErrDeviceCodeExpired: %v", err)
+	}
+}
+
+func TestDeviceFlowContextCancelled(t *testing.T) {
+	fake := &fakeTwitch{devicePolls: []string{"authorization_pending", "authorization_pending", "authorization_pending"}}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	flow := newFakeTwitchClient(server).NewDeviceFlow(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := flow.Wait(ctx, &DeviceCode{DeviceCode: "device-code", Interval: 1, ExpiresIn: 1800})
+	if err != context.DeadlineExceeded {
+		t.Errorf("DeviceFlowContextCancelled error was not context.DeadlineExceeded: %v", err)
+	}
+}
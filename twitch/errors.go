@@ -0,0 +1,141 @@
+package twitch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySnippet the maximum number of body bytes kept on a DecodeError
+const maxErrorBodySnippet = 512
+
+// Sentinel errors that can be used with errors.Is to classify a failure
+var (
+	// ErrNotFound the requested resource does not exist
+	ErrNotFound = errors.New("twitch: not found")
+	// ErrUnauthorized the access token or client ID was missing, invalid or expired
+	ErrUnauthorized = errors.New("twitch: unauthorized")
+	// ErrForbiddenScope the access token does not have the scope required by the endpoint
+	ErrForbiddenScope = errors.New("twitch: forbidden scope")
+	// ErrRateLimited too many requests have been made with the client ID
+	ErrRateLimited = errors.New("twitch: rate limited")
+	// ErrServer twitch failed to handle the request
+	ErrServer = errors.New("twitch: server error")
+)
+
+//APIError an error response returned by the Twitch API
+type APIError struct {
+	StatusCode int
+	ErrorText  string
+	Message    string
+}
+
+// Error - the status and message of the API error
+func (e *APIError) Error() string {
+	errorText := e.ErrorText
+	if errorText == "" {
+		errorText = http.StatusText(e.StatusCode)
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("twitch: %d %s", e.StatusCode, errorText)
+	}
+	return fmt.Sprintf("twitch: %d %s: %s", e.StatusCode, errorText, e.Message)
+}
+
+// Is - match the API error against the sentinel errors based on the status code
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized && !e.isScopeError()
+	case ErrForbiddenScope:
+		return e.StatusCode == http.StatusForbidden || (e.StatusCode == http.StatusUnauthorized && e.isScopeError())
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// isScopeError - twitch reports missing scopes as a 401 with a message naming the scope
+func (e *APIError) isScopeError() bool {
+	return strings.Contains(strings.ToLower(e.Message), "scope")
+}
+
+//TransportError the request could not be sent or the response could not be read
+type TransportError struct {
+	Err error
+}
+
+// Error - the message of the original cause
+func (e *TransportError) Error() string {
+	return "twitch: transport error: " + e.Err.Error()
+}
+
+// Unwrap - the original cause, e.g. context.Canceled or a *url.Error
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+//DecodeError the response body could not be decoded
+type DecodeError struct {
+	Err  error
+	Body string // The start of the body that failed to decode
+}
+
+// Error - the decoding error and the offending body
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("twitch: decode error: %s: %q", e.Err.Error(), e.Body)
+}
+
+// Unwrap - the original decoding error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Err - the typed error behind the error output, for use with errors.Is and errors.As
+func (e *ErrorOutput) Err() error {
+	if e == nil {
+		return nil
+	}
+	if e.err != nil {
+		return e.err
+	}
+	return &APIError{
+		StatusCode: int(e.Status),
+		ErrorText:  e.Error,
+		Message:    e.Message,
+	}
+}
+
+//newAPIErrorOutput create the error output for a non successful response
+func newAPIErrorOutput(statusCode int, errorOutput *ErrorOutput) *ErrorOutput {
+	if errorOutput.Status == 0 {
+		errorOutput.Status = int64(statusCode)
+	}
+	if errorOutput.Error == "" {
+		errorOutput.Error = http.StatusText(statusCode)
+	}
+	errorOutput.err = &APIError{
+		StatusCode: statusCode,
+		ErrorText:  errorOutput.Error,
+		Message:    errorOutput.Message,
+	}
+	return errorOutput
+}
+
+//newDecodeErrorOutput create the error output for a response body that couldn't be decoded
+func newDecodeErrorOutput(err error, body []byte) *ErrorOutput {
+	if len(body) > maxErrorBodySnippet {
+		body = body[:maxErrorBodySnippet]
+	}
+	return &ErrorOutput{
+		Message: err.Error(),
+		Error:   "Twitchy error",
+		Status:  -1,
+		err:     &DecodeError{Err: err, Body: string(body)},
+	}
+}
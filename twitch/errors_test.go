@@ -0,0 +1,140 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestErrorOutputSentinels(t *testing.T) {
+	tests := []struct {
+		status   int
+		body     string
+		sentinel error
+	}{
+		{404, `{"error":"Not Found","status":404,"message":"Channel '1234' does not exist"}`, ErrNotFound},
+		{401, `{"error":"Unauthorized","status":401,"message":"invalid oauth token"}`, ErrUnauthorized},
+		{401, `{"error":"Unauthorized","status":401,"message":"missing required oauth scope"}`, ErrForbiddenScope},
+		{403, `{"error":"Forbidden","status":403,"message":"Forbidden"}`, ErrForbiddenScope},
+		{429, `{"error":"Too Many Requests","status":429,"message":"slow down"}`, ErrRateLimited},
+		{503, ``, ErrServer},
+	}
+
+	for _, test := range tests {
+		httpmock.Activate()
+		httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+			httpmock.NewStringResponder(test.status, test.body))
+
+		client := NewClient(&OAuthConfig{}, &http.Client{})
+		_, errorOutput := client.GetRoot()
+		httpmock.DeactivateAndReset()
+
+		if errorOutput == nil {
+			t.Fatalf("ErrorOutputSentinels %d errorOutput shouldn't have been nil", test.status)
+		}
+		if errorOutput.Status != int64(test.status) {
+			t.Errorf("ErrorOutputSentinels error.Status was not %d: %d", test.status, errorOutput.Status)
+		}
+		err := errorOutput.Err()
+		if !errors.Is(err, test.sentinel) {
+			t.Errorf("ErrorOutputSentinels %d error was not %v: %v", test.status, test.sentinel, err)
+		}
+		for _, other := range []error{ErrNotFound, ErrUnauthorized, ErrForbiddenScope, ErrRateLimited, ErrServer} {
+			if other != test.sentinel && errors.Is(err, other) {
+				t.Errorf("ErrorOutputSentinels %d error should not have been %v: %v", test.status, other, err)
+			}
+		}
+		apiError := &APIError{}
+		if !errors.As(err, &apiError) {
+			t.Fatalf("ErrorOutputSentinels %d error was not an *APIError: %T", test.status, err)
+		}
+		if apiError.StatusCode != test.status {
+			t.Errorf("ErrorOutputSentinels APIError.StatusCode was not %d: %d", test.status, apiError.StatusCode)
+		}
+	}
+}
+
+func TestErrorOutputAPIErrorMessage(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(404, `{"error":"Not Found","status":404,"message":"Channel '1234' does not exist"}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	_, errorOutput := client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+
+	if errorOutput.Error != "Not Found" {
+		t.Errorf("ErrorOutputAPIErrorMessage error.Error was not \"Not Found\": %s", errorOutput.Error)
+	}
+	if errorOutput.Message != "Channel '1234' does not exist" {
+		t.Errorf("ErrorOutputAPIErrorMessage error.Message was not \"Channel '1234' does not exist\": %s", errorOutput.Message)
+	}
+	expected := "twitch: 404 Not Found: Channel '1234' does not exist"
+	if errorOutput.Err().Error() != expected {
+		t.Errorf("ErrorOutputAPIErrorMessage Err() was not %q: %q", expected, errorOutput.Err().Error())
+	}
+}
+
+func TestErrorOutputTransportError(t *testing.T) {
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, errorOutput := client.GetRootWithContext(ctx)
+
+	if errorOutput.Status != -1 {
+		t.Errorf("ErrorOutputTransportError error.Status was not -1: %d", errorOutput.Status)
+	}
+	err := errorOutput.Err()
+	transportError := &TransportError{}
+	if !errors.As(err, &transportError) {
+		t.Fatalf("ErrorOutputTransportError error was not a *TransportError: %T", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ErrorOutputTransportError error did not wrap context.Canceled: %v", err)
+	}
+}
+
+func TestErrorOutputDecodeError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	body := `{"foo":{{]]"}}` + strings.Repeat("x", 1000)
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		httpmock.NewStringResponder(200, body))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	_, errorOutput := client.GetRoot()
+
+	err := errorOutput.Err()
+	decodeError := &DecodeError{}
+	if !errors.As(err, &decodeError) {
+		t.Fatalf("ErrorOutputDecodeError error was not a *DecodeError: %T", err)
+	}
+	if decodeError.Body != body[:maxErrorBodySnippet] {
+		t.Errorf("ErrorOutputDecodeError body snippet was not the first %d bytes: %s", maxErrorBodySnippet, decodeError.Body)
+	}
+	if errorOutput.Message != decodeError.Err.Error() {
+		t.Errorf("ErrorOutputDecodeError error.Message was not the decode error: %s", errorOutput.Message)
+	}
+}
+
+func TestErrorOutputErrWithoutCause(t *testing.T) {
+	var nilOutput *ErrorOutput
+	if nilOutput.Err() != nil {
+		t.Errorf("ErrorOutputErrWithoutCause nil error output should have a nil Err()")
+	}
+
+	errorOutput := &ErrorOutput{Error: "Unauthorized", Status: 401, Message: "invalid oauth token"}
+	if !errors.Is(errorOutput.Err(), ErrUnauthorized) {
+		t.Errorf("ErrorOutputErrWithoutCause error was not ErrUnauthorized: %v", errorOutput.Err())
+	}
+}
@@ -1,7 +1,9 @@
 package twitch
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -90,13 +92,54 @@ type DeleteChannelFeedPostReactionOutput struct{}
 
 // ListChannelFeedPosts - List channel feed posts
 func (c *Client) ListChannelFeedPosts(input *ListChannelFeedPostsInput) (*ListChannelFeedPostsOutput, *ErrorOutput) {
+	return c.ListChannelFeedPostsWithContext(context.Background(), input)
+}
+
+// ListChannelFeedPostsWithContext - the same as ListChannelFeedPosts but the request is bound to the context
+func (c *Client) ListChannelFeedPostsWithContext(ctx context.Context, input *ListChannelFeedPostsInput) (*ListChannelFeedPostsOutput, *ErrorOutput) {
+	params := map[string]string{}
+	if input.Limit != 0 {
+		params["limit"] = strconv.Itoa(input.Limit)
+	}
+	if input.Cursor != "" {
+		params["cursor"] = input.Cursor
+	}
 	output := new(ListChannelFeedPostsOutput)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("feed/%d/posts", input.ChannelID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "ListChannelFeedPosts", "GET", fmt.Sprintf("feed/%d/posts", input.ChannelID), params, output)
 	return output, errorOutput
 }
 
+// ListChannelFeedPostsPages - walk every page of channel feed posts, fn returns false to stop early
+func (c *Client) ListChannelFeedPostsPages(input *ListChannelFeedPostsInput, fn func(page *ListChannelFeedPostsOutput, lastPage bool) bool) *ErrorOutput {
+	return c.ListChannelFeedPostsPagesWithContext(context.Background(), input, fn)
+}
+
+// ListChannelFeedPostsPagesWithContext - the same as ListChannelFeedPostsPages but the requests are bound to the context
+func (c *Client) ListChannelFeedPostsPagesWithContext(ctx context.Context, input *ListChannelFeedPostsInput, fn func(page *ListChannelFeedPostsOutput, lastPage bool) bool) *ErrorOutput {
+	return c.traceCalls(ctx, "ListChannelFeedPostsPages", func(ctx context.Context) *ErrorOutput {
+		pageInput := *input
+		pageInput.Limit = int(pageLimit(int64(input.Limit)))
+		for {
+			output, errorOutput := c.ListChannelFeedPostsWithContext(ctx, &pageInput)
+			if errorOutput != nil {
+				return errorOutput
+			}
+			pageInput.Cursor = output.Cursor
+			lastPage := output.Cursor == "" || len(output.Posts) == 0
+			if !fn(output, lastPage) || lastPage {
+				return nil
+			}
+		}
+	})
+}
+
 // CreateChannelFeedPost - create a post for a channel feed
 func (c *Client) CreateChannelFeedPost(input *CreateChannelFeedPostInput) (*CreateChannelFeedPostOutput, *ErrorOutput) {
+	return c.CreateChannelFeedPostWithContext(context.Background(), input)
+}
+
+// CreateChannelFeedPostWithContext - the same as CreateChannelFeedPost but the request is bound to the context
+func (c *Client) CreateChannelFeedPostWithContext(ctx context.Context, input *CreateChannelFeedPostInput) (*CreateChannelFeedPostOutput, *ErrorOutput) {
 	params := map[string]string{}
 	params["content"] = input.Content
 	if input.Share == true {
@@ -105,38 +148,58 @@ func (c *Client) CreateChannelFeedPost(input *CreateChannelFeedPostInput) (*Crea
 		params["share"] = "false"
 	}
 	output := new(CreateChannelFeedPostOutput)
-	errorOutput := c.sendAPIRequest("POST", fmt.Sprintf("feed/%d/posts", input.ChannelID), params, output)
+	errorOutput := c.sendAPIRequest(ctx, "CreateChannelFeedPost", "POST", fmt.Sprintf("feed/%d/posts", input.ChannelID), params, output)
 	return output, errorOutput
 }
 
 // GetChannelFeedPost - Get a single channel feed post
 func (c *Client) GetChannelFeedPost(input *GetChannelFeedPostInput) (*GetChannelFeedPostOutput, *ErrorOutput) {
+	return c.GetChannelFeedPostWithContext(context.Background(), input)
+}
+
+// GetChannelFeedPostWithContext - the same as GetChannelFeedPost but the request is bound to the context
+func (c *Client) GetChannelFeedPostWithContext(ctx context.Context, input *GetChannelFeedPostInput) (*GetChannelFeedPostOutput, *ErrorOutput) {
 	output := new(GetChannelFeedPostOutput)
-	errorOutput := c.sendAPIRequest("GET", fmt.Sprintf("feed/%d/posts/%s", input.ChannelID, input.PostID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetChannelFeedPost", "GET", fmt.Sprintf("feed/%d/posts/%s", input.ChannelID, input.PostID), nil, output)
 	return output, errorOutput
 }
 
 // DeleteChannelFeedPost - Delete a single channel feed post
 func (c *Client) DeleteChannelFeedPost(input *DeleteChannelFeedPostInput) (*DeleteChannelFeedPostOutput, *ErrorOutput) {
+	return c.DeleteChannelFeedPostWithContext(context.Background(), input)
+}
+
+// DeleteChannelFeedPostWithContext - the same as DeleteChannelFeedPost but the request is bound to the context
+func (c *Client) DeleteChannelFeedPostWithContext(ctx context.Context, input *DeleteChannelFeedPostInput) (*DeleteChannelFeedPostOutput, *ErrorOutput) {
 	output := new(DeleteChannelFeedPostOutput)
-	errorOutput := c.sendAPIRequest("DELETE", fmt.Sprintf("feed/%d/posts/%s", input.ChannelID, input.PostID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "DeleteChannelFeedPost", "DELETE", fmt.Sprintf("feed/%d/posts/%s", input.ChannelID, input.PostID), nil, output)
 	return output, errorOutput
 }
 
 // CreateChannelFeedPostReaction - create a reaction to a post on a channel feed
 func (c *Client) CreateChannelFeedPostReaction(input *CreateChannelFeedPostReactionInput) (*CreateChannelFeedPostReactionOutput, *ErrorOutput) {
+	return c.CreateChannelFeedPostReactionWithContext(context.Background(), input)
+}
+
+// CreateChannelFeedPostReactionWithContext - the same as CreateChannelFeedPostReaction but the request is bound to the context
+func (c *Client) CreateChannelFeedPostReactionWithContext(ctx context.Context, input *CreateChannelFeedPostReactionInput) (*CreateChannelFeedPostReactionOutput, *ErrorOutput) {
 	params := map[string]string{}
 	params["emote_id"] = input.EmoteID
 	output := new(CreateChannelFeedPostReactionOutput)
-	errorOutput := c.sendAPIRequest("POST", fmt.Sprintf("feed/%d/posts/%s/reactions", input.ChannelID, input.PostID), params, output)
+	errorOutput := c.sendAPIRequest(ctx, "CreateChannelFeedPostReaction", "POST", fmt.Sprintf("feed/%d/posts/%s/reactions", input.ChannelID, input.PostID), params, output)
 	return output, errorOutput
 }
 
 // DeleteChannelFeedPostReaction - Delete a single channel feed post reaction
 func (c *Client) DeleteChannelFeedPostReaction(input *DeleteChannelFeedPostReactionInput) (*DeleteChannelFeedPostReactionOutput, *ErrorOutput) {
+	return c.DeleteChannelFeedPostReactionWithContext(context.Background(), input)
+}
+
+// DeleteChannelFeedPostReactionWithContext - the same as DeleteChannelFeedPostReaction but the request is bound to the context
+func (c *Client) DeleteChannelFeedPostReactionWithContext(ctx context.Context, input *DeleteChannelFeedPostReactionInput) (*DeleteChannelFeedPostReactionOutput, *ErrorOutput) {
 	params := map[string]string{}
 	params["emote_id"] = input.EmoteID
 	output := new(DeleteChannelFeedPostReactionOutput)
-	errorOutput := c.sendAPIRequest("DELETE", fmt.Sprintf("feed/%d/posts/%s/reactions", input.ChannelID, input.PostID), nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "DeleteChannelFeedPostReaction", "DELETE", fmt.Sprintf("feed/%d/posts/%s/reactions", input.ChannelID, input.PostID), nil, output)
 	return output, errorOutput
 }
@@ -1,6 +1,8 @@
 package twitch
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"testing"
 
@@ -257,3 +259,28 @@ func TestDeleteChannelFeedPostReaction(t *testing.T) {
 		t.Errorf("DeleteChannelFeedPostReaction output shouldn't have been nil")
 	}
 }
+
+func TestListChannelFeedPostsPagesWithContext(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/feed/12345/posts",
+		httpmock.NewStringResponder(200, `{"_total":8,"_cursor":"next","posts":[{"id":"20"}]}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pages := 0
+	errorOutput := client.ListChannelFeedPostsPagesWithContext(ctx, &ListChannelFeedPostsInput{ChannelID: 12345}, func(page *ListChannelFeedPostsOutput, lastPage bool) bool {
+		pages++
+		cancel()
+		return true
+	})
+
+	if errorOutput == nil || !errors.Is(errorOutput.Err(), context.Canceled) {
+		t.Errorf("ListChannelFeedPostsPagesWithContext the cancelled context was not returned: %+v", errorOutput)
+	}
+	if pages != 1 {
+		t.Errorf("ListChannelFeedPostsPagesWithContext the pages after the cancel were walked: %d", pages)
+	}
+}
@@ -0,0 +1,104 @@
+package twitch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//FileCache a Cache that keeps each entry in a JSON file in a directory, so entries survive restarts
+type FileCache struct {
+	mu  sync.Mutex
+	dir string
+}
+
+//NewFileCache create a cache in the directory, it is created when it doesn't exist
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+// Get - the entry for the key, or nil when it isn't stored or can't be read
+func (f *FileCache) Get(key string) *CacheEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.read(f.path(key))
+}
+
+// Set - store the entry, it isn't stored when it can't be written
+func (f *FileCache) Set(key string, entry *CacheEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	writeFileAtomic(f.path(key), data)
+}
+
+// Invalidate - remove every entry for the resource
+func (f *FileCache) Invalidate(resource string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	files, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(f.dir, file.Name())
+		if entry := f.read(path); entry == nil || entry.Resource == resource {
+			os.Remove(path)
+		}
+	}
+}
+
+//path the file of the key, keys contain URLs so they are hashed
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+//read the entry in the file, or nil
+func (f *FileCache) read(path string) *CacheEntry {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	entry := &CacheEntry{}
+	if json.Unmarshal(data, entry) != nil {
+		return nil
+	}
+	return entry
+}
+
+//writeFileAtomic write to a temporary file then rename it so a reader never sees half a file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
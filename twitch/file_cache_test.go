@@ -0,0 +1,114 @@
+package twitch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestFileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "twitch-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := NewFileCache(filepath.Join(dir, "entries"))
+	if err != nil {
+		t.Fatalf("NewFileCache err should have been nil: %s", err)
+	}
+	expiry := time.Unix(1500000000, 0).UTC()
+	cache.Set("key", &CacheEntry{Resource: "channel/1234", ETag: `"v1"`, Body: []byte(`{"_id":"1234"}`), Expiry: expiry})
+	cache.Set("other", &CacheEntry{Resource: "channel/5678", Body: []byte(`{"_id":"5678"}`)})
+
+	// A new cache in the same directory sees the entries
+	reopened, _ := NewFileCache(filepath.Join(dir, "entries"))
+	entry := reopened.Get("key")
+	if entry == nil {
+		t.Fatal("FileCache the entry was not stored")
+	}
+	if entry.ETag != `"v1"` || string(entry.Body) != `{"_id":"1234"}` || !entry.Expiry.Equal(expiry) {
+		t.Errorf("FileCache the entry was not correct: %+v", entry)
+	}
+	if reopened.Get("missing") != nil {
+		t.Error("FileCache a missing entry should have been nil")
+	}
+
+	reopened.Invalidate("channel/1234")
+	if reopened.Get("key") != nil {
+		t.Error("FileCache the entry for the resource was not removed")
+	}
+	if reopened.Get("other") == nil {
+		t.Error("FileCache the entry for another resource was removed")
+	}
+}
+
+func TestFileCacheClient(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"Playing"}`))
+
+	dir, err := ioutil.TempDir("", "twitch-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, _ := NewFileCache(dir)
+	client := NewClient(&OAuthConfig{AccessToken: "access-token"}, &http.Client{}, WithCache(cache, time.Minute))
+
+	client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+	output, errorOutput := client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+
+	if errorOutput != nil {
+		t.Errorf("GetChannelByID errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.Status != "Playing" {
+		t.Errorf("GetChannelByID the status was not Playing: %s", output.Status)
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("The cached response was not used: %d", httpmock.GetTotalCallCount())
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	for _, file := range files {
+		data, _ := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if filepath.Ext(file.Name()) != ".json" {
+			t.Errorf("FileCache a temporary file was left behind: %s", file.Name())
+		}
+		if strings.Contains(string(data), "access-token") {
+			t.Errorf("FileCache the access token was written to disk: %s", file.Name())
+		}
+	}
+}
+
+func TestFileCacheAuthenticatedChannel(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(200, `{"_id":"1234","stream_key":"live_1234_secret","email":"user@example.com"}`))
+
+	dir, err := ioutil.TempDir("", "twitch-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache, _ := NewFileCache(dir)
+	client := NewClient(&OAuthConfig{AccessToken: "access-token"}, &http.Client{}, WithCache(cache, time.Minute))
+
+	client.GetChannel()
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 0 {
+		t.Errorf("FileCache the stream key and email should not have been written to disk: %d files", len(files))
+	}
+}
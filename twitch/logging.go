@@ -0,0 +1,189 @@
+package twitch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxLogBody the most bytes of a request or response body that are logged
+const maxLogBody = 2048
+
+// redacted replaces the values of secrets in the logs
+const redacted = "[REDACTED]"
+
+// redactedHeaders the headers that are never logged
+var redactedHeaders = []string{"Authorization", "Client-Id"}
+
+// redactedFields the query, form and JSON fields that are never logged
+var redactedFields = []string{
+	"stream_key",
+	"email",
+	"upload_token",
+	"token",
+	"access_token",
+	"refresh_token",
+	"client_secret",
+	"code",
+	"device_code",
+}
+
+//formBodyContextKey the context key marking a request built with a form body, the body is redacted as a form when logged
+type formBodyContextKey struct{}
+
+//withFormBody mark the request as built with a form body, the mark isn't sent to Twitch
+func withFormBody(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), formBodyContextKey{}, true))
+}
+
+//Logger receives a debug log of every request, *slog.Logger from log/slog satisfies it
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// SetLogger - log every request and response to the logger, nil disables logging
+func (c *Client) SetLogger(logger Logger) {
+	c.logger = logger
+}
+
+// SetBodyLogging - include the request and response bodies in the logs, secrets are redacted
+func (c *Client) SetBodyLogging(enabled bool) {
+	c.logBodies = enabled
+}
+
+//WithLogger log every request and response to the logger
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Client) {
+		c.SetLogger(logger)
+	}
+}
+
+//WithBodyLogging include the request and response bodies in the logs, secrets are redacted
+func WithBodyLogging() ClientOption {
+	return func(c *Client) {
+		c.SetBodyLogging(true)
+	}
+}
+
+//logRequest log a request once it has been sent, resp is nil when there was no response
+func (c *Client) logRequest(ctx context.Context, req *http.Request, resp *http.Response, body []byte, latency time.Duration, err error) {
+	args := []interface{}{
+		"method", req.Method,
+		"endpoint", EndpointFromContext(ctx),
+		"url", redactURL(req.URL),
+		"latency", latency,
+		"request_headers", redactHeader(req.Header),
+	}
+	if c.logBodies && strings.HasPrefix(req.URL.String(), c.uploadURL) {
+		// Upload bodies are parts of a video, they are logged by size without reading them again
+		if req.ContentLength > 0 {
+			args = append(args, "request_body", fmt.Sprintf("[%d bytes]", req.ContentLength))
+		}
+	} else if c.logBodies && req.GetBody != nil {
+		if reqBody, bodyErr := req.GetBody(); bodyErr == nil {
+			data, _ := ioutil.ReadAll(reqBody)
+			reqBody.Close()
+			contentType := req.Header.Get("Content-Type")
+			if req.Context().Value(formBodyContextKey{}) != nil {
+				contentType = "application/x-www-form-urlencoded"
+			}
+			args = append(args, "request_body", redactBody(contentType, data))
+		}
+	}
+	if resp != nil {
+		args = append(args,
+			"status", resp.StatusCode,
+			"ratelimit_limit", resp.Header.Get("Ratelimit-Limit"),
+			"ratelimit_remaining", resp.Header.Get("Ratelimit-Remaining"),
+			"ratelimit_reset", resp.Header.Get("Ratelimit-Reset"),
+		)
+		if c.logBodies && body != nil {
+			args = append(args, "response_body", redactBody(resp.Header.Get("Content-Type"), body))
+		}
+	}
+	if err != nil {
+		args = append(args, "error", err.Error())
+	}
+	c.logger.DebugContext(ctx, "twitch request", args...)
+}
+
+//redactURL the URL with secrets in the query removed
+func redactURL(u *url.URL) string {
+	redactedURL := *u
+	redactedURL.RawQuery = redactValues(u.Query()).Encode()
+	return redactedURL.String()
+}
+
+//redactHeader a copy of the header with the authorization headers removed
+func redactHeader(header http.Header) http.Header {
+	redactedHeader := header.Clone()
+	for _, name := range redactedHeaders {
+		if redactedHeader.Get(name) != "" {
+			redactedHeader.Set(name, redacted)
+		}
+	}
+	return redactedHeader
+}
+
+//redactValues a copy of the values with secrets removed
+func redactValues(values url.Values) url.Values {
+	redactedValues := url.Values{}
+	for key, value := range values {
+		if containsString(redactedFields, strings.ToLower(key)) {
+			value = []string{redacted}
+		}
+		redactedValues[key] = value
+	}
+	return redactedValues
+}
+
+//redactBody the body as a string with secrets removed, bodies that aren't JSON or a form are only logged by size
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var text string
+	var decoded interface{}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return fmt.Sprintf("[%d bytes]", len(body))
+		}
+		text = redactValues(values).Encode()
+	} else if json.Unmarshal(body, &decoded) == nil {
+		encoded, _ := json.Marshal(redactJSON(decoded))
+		text = string(encoded)
+	} else {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+
+	if len(text) > maxLogBody {
+		text = text[:maxLogBody] + "..."
+	}
+	return text
+}
+
+//redactJSON the decoded JSON with secrets removed at any depth
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if containsString(redactedFields, strings.ToLower(key)) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactJSON(child)
+		}
+	}
+	return value
+}
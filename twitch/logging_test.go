@@ -0,0 +1,223 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+type testLogger struct {
+	messages []string
+	entries  []map[string]interface{}
+}
+
+func (l *testLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	entry := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		entry[args[i].(string)] = args[i+1]
+	}
+	l.messages = append(l.messages, msg)
+	l.entries = append(l.entries, entry)
+}
+
+func TestLoggerRequest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"_id":"1234","stream_key":"live_1234_secret","email":"user@example.com"}`)
+			resp.Header.Set("Ratelimit-Limit", "800")
+			resp.Header.Set("Ratelimit-Remaining", "799")
+			resp.Header.Set("Ratelimit-Reset", "1500000060")
+			return resp, nil
+		})
+
+	logger := &testLogger{}
+	client := NewClient(&OAuthConfig{ClientID: "client-id", AccessToken: "access-token"}, &http.Client{}, WithLogger(logger))
+
+	_, errorOutput := client.GetChannel()
+	if errorOutput != nil {
+		t.Errorf("GetChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("The request was not logged once: %d", len(logger.entries))
+	}
+	entry := logger.entries[0]
+	if logger.messages[0] != "twitch request" {
+		t.Errorf("The log message was not correct: %s", logger.messages[0])
+	}
+	if entry["method"] != "GET" {
+		t.Errorf("The logged method was not GET: %v", entry["method"])
+	}
+	if entry["endpoint"] != "GetChannel" {
+		t.Errorf("The logged endpoint was not GetChannel: %v", entry["endpoint"])
+	}
+	if entry["status"] != 200 {
+		t.Errorf("The logged status was not 200: %v", entry["status"])
+	}
+	if entry["ratelimit_remaining"] != "799" {
+		t.Errorf("The logged rate limit remaining was not 799: %v", entry["ratelimit_remaining"])
+	}
+	if _, ok := entry["latency"]; !ok {
+		t.Error("The latency was not logged")
+	}
+	if _, ok := entry["response_body"]; ok {
+		t.Error("The response body should not have been logged")
+	}
+
+	logged := fmt.Sprint(entry)
+	if strings.Contains(logged, "access-token") || strings.Contains(logged, "client-id") {
+		t.Errorf("The authorization headers were not redacted: %s", logged)
+	}
+}
+
+func TestLoggerBodies(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("PUT", "https://api.twitch.tv/kraken/channel/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"Playing","stream_key":"live_1234_secret","email":"user@example.com"}`))
+
+	logger := &testLogger{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithLogger(logger), WithBodyLogging())
+
+	_, errorOutput := client.UpdateChannel(&UpdateChannelInput{ChannelID: 1234, Status: "Playing"})
+	if errorOutput != nil {
+		t.Errorf("UpdateChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+
+	entry := logger.entries[0]
+	responseBody, _ := entry["response_body"].(string)
+	if !strings.Contains(responseBody, `"status":"Playing"`) {
+		t.Errorf("The response body was not logged: %s", responseBody)
+	}
+	if strings.Contains(responseBody, "live_1234_secret") || strings.Contains(responseBody, "user@example.com") {
+		t.Errorf("The response body was not redacted: %s", responseBody)
+	}
+	requestBody, _ := entry["request_body"].(string)
+	if !strings.Contains(requestBody, "status=Playing") {
+		t.Errorf("The request body was not logged: %s", requestBody)
+	}
+}
+
+func TestLoggerFormBodyRedacted(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var contentType string
+	httpmock.RegisterResponder("PUT", "https://api.twitch.tv/kraken/channel/1234",
+		func(req *http.Request) (*http.Response, error) {
+			contentType = req.Header.Get("Content-Type")
+			return httpmock.NewStringResponse(200, `{"_id":"1234"}`), nil
+		})
+
+	logger := &testLogger{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithLogger(logger), WithBodyLogging())
+
+	client.sendAPIRequest(context.Background(), "Test", "PUT", "channel/1234", map[string]string{
+		"stream_key": "live_1234_secret",
+		"status":     "Playing",
+	}, nil)
+
+	requestBody, _ := logger.entries[0]["request_body"].(string)
+	if !strings.Contains(requestBody, "status=Playing") || !strings.Contains(requestBody, "stream_key=%5BREDACTED%5D") {
+		t.Errorf("The form body was not logged with the secrets redacted: %s", requestBody)
+	}
+	if strings.Contains(requestBody, "live_1234_secret") {
+		t.Errorf("The form body was not redacted: %s", requestBody)
+	}
+	if contentType != "" {
+		t.Errorf("Logging should not have changed the request sent: %s", contentType)
+	}
+}
+
+func TestLoggerUploadToken(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("PUT", "https://uploads.twitch.tv/upload/1234",
+		httpmock.NewStringResponder(200, ""))
+
+	logger := &testLogger{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithLogger(logger), WithBodyLogging())
+
+	client.UploadVideoPart(&UploadVideoPartInput{
+		VideoID: "v1234",
+		Token:   "upload-secret",
+		Part:    1,
+		Body:    bytes.NewBuffer([]byte{0, 0, 0, 24, 'f', 't', 'y', 'p'}),
+	})
+
+	entry := logger.entries[0]
+	if strings.Contains(fmt.Sprint(entry), "upload-secret") {
+		t.Errorf("The upload token was not redacted: %v", entry["url"])
+	}
+	if entry["request_body"] != "[8 bytes]" {
+		t.Errorf("The video part should only have been logged by size: %v", entry["request_body"])
+	}
+}
+
+func TestLoggerUploadBodyNotRead(t *testing.T) {
+	logger := &testLogger{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithLogger(logger), WithBodyLogging())
+
+	req, _ := http.NewRequest("PUT", client.uploadURL+"upload/1234?part=1", nil)
+	req.ContentLength = DefaultPartSize
+	req.GetBody = func() (io.ReadCloser, error) {
+		t.Error("The video part should not have been read to be logged")
+		return http.NoBody, nil
+	}
+	client.logRequest(context.Background(), req, nil, nil, time.Second, nil)
+
+	if logger.entries[0]["request_body"] != fmt.Sprintf("[%d bytes]", DefaultPartSize) {
+		t.Errorf("The video part was not logged by its Content-Length: %v", logger.entries[0]["request_body"])
+	}
+}
+
+func TestLoggerTransportError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewErrorResponder(errors.New("connection refused")))
+
+	logger := &testLogger{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithLogger(logger))
+
+	client.GetChannel()
+
+	entry := logger.entries[0]
+	if !strings.Contains(fmt.Sprint(entry["error"]), "connection refused") {
+		t.Errorf("The transport error was not logged: %v", entry["error"])
+	}
+	if _, ok := entry["status"]; ok {
+		t.Error("The status should not have been logged without a response")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	form := redactBody("application/x-www-form-urlencoded", []byte("client_id=abc&client_secret=s3cr3t&refresh_token=refresh"))
+	if strings.Contains(form, "s3cr3t") || strings.Contains(form, "=refresh") || !strings.Contains(form, "client_id=abc") {
+		t.Errorf("The form was not redacted: %s", form)
+	}
+
+	nested := redactBody("application/json", []byte(`{"video":{"_id":"v1"},"upload":{"url":"https://uploads.twitch.tv/upload/1","token":"upload-secret"}}`))
+	if strings.Contains(nested, "upload-secret") || !strings.Contains(nested, "v1") {
+		t.Errorf("The nested JSON was not redacted: %s", nested)
+	}
+
+	long := redactBody("application/json", []byte(`"`+strings.Repeat("a", maxLogBody*2)+`"`))
+	if len(long) != maxLogBody+3 {
+		t.Errorf("The body was not truncated: %d", len(long))
+	}
+}
@@ -0,0 +1,48 @@
+package twitch
+
+import (
+	"fmt"
+	"time"
+)
+
+//Metrics records the outcome of every request, e.g. to count calls and errors per endpoint
+type Metrics interface {
+	ObserveRequest(metric *RequestMetric)
+}
+
+//RequestMetric the outcome of a request, including all of its retries
+type RequestMetric struct {
+	Endpoint    string // e.g. "GetChannelFollowers" or "UploadVideoPart"
+	StatusClass string // "2xx", "4xx", "5xx" or "error" when there was no response
+	Retries     int
+	Duration    time.Duration
+}
+
+// SetMetrics - record the outcome of every request, nil disables metrics
+func (c *Client) SetMetrics(metrics Metrics) {
+	c.metrics = metrics
+}
+
+//WithMetrics record the outcome of every request
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(c *Client) {
+		c.SetMetrics(metrics)
+	}
+}
+
+//newRequestMetric the metric of a request that has finished with errorOutput
+func newRequestMetric(endpoint string, errorOutput *ErrorOutput, retries int, duration time.Duration) *RequestMetric {
+	metric := &RequestMetric{
+		Endpoint:    endpoint,
+		StatusClass: "2xx",
+		Retries:     retries,
+		Duration:    duration,
+	}
+	if errorOutput != nil {
+		metric.StatusClass = "error"
+		if errorOutput.Status > 0 {
+			metric.StatusClass = fmt.Sprintf("%dxx", errorOutput.Status/100)
+		}
+	}
+	return metric
+}
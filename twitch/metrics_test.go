@@ -0,0 +1,98 @@
+package twitch
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+type testMetrics struct {
+	metrics []*RequestMetric
+}
+
+func (m *testMetrics) ObserveRequest(metric *RequestMetric) {
+	m.metrics = append(m.metrics, metric)
+}
+
+func TestMetricsSuccess(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(200, `{"_id":"1234"}`))
+
+	now := time.Unix(1500000000, 0)
+	metrics := &testMetrics{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithMetrics(metrics), WithClock(func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}))
+
+	client.GetChannel()
+
+	if len(metrics.metrics) != 1 {
+		t.Fatalf("The request was not observed once: %d", len(metrics.metrics))
+	}
+	metric := metrics.metrics[0]
+	if metric.Endpoint != "GetChannel" {
+		t.Errorf("The metric endpoint was not GetChannel: %s", metric.Endpoint)
+	}
+	if metric.StatusClass != "2xx" {
+		t.Errorf("The metric status class was not 2xx: %s", metric.StatusClass)
+	}
+	if metric.Retries != 0 {
+		t.Errorf("The metric retries was not 0: %d", metric.Retries)
+	}
+	if metric.Duration <= 0 {
+		t.Errorf("The metric duration was not recorded: %s", metric.Duration)
+	}
+}
+
+func TestMetricsRetries(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(503, `{"error":"Service Unavailable","status":503,"message":""}`))
+
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	metrics := &testMetrics{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithMetrics(metrics), WithRetryPolicy(policy))
+
+	client.GetChannel()
+
+	metric := metrics.metrics[0]
+	if metric.StatusClass != "5xx" {
+		t.Errorf("The metric status class was not 5xx: %s", metric.StatusClass)
+	}
+	if metric.Retries != 2 {
+		t.Errorf("The metric retries was not 2: %d", metric.Retries)
+	}
+}
+
+func TestMetricsTransportError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("PUT", "https://uploads.twitch.tv/upload/1234",
+		httpmock.NewErrorResponder(errors.New("connection reset")))
+
+	metrics := &testMetrics{}
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	client.SetMetrics(metrics)
+
+	client.UploadVideoPart(&UploadVideoPartInput{VideoID: "v1234", Token: "token", Part: 1, Body: bytes.NewBufferString("part")})
+
+	metric := metrics.metrics[0]
+	if metric.Endpoint != "UploadVideoPart" {
+		t.Errorf("The metric endpoint was not UploadVideoPart: %s", metric.Endpoint)
+	}
+	if metric.StatusClass != "error" {
+		t.Errorf("The metric status class was not error: %s", metric.StatusClass)
+	}
+}
@@ -0,0 +1,103 @@
+package twitch
+
+import (
+	"net/http"
+	"time"
+)
+
+//RoundTripFunc sends a request and returns the response, like http.RoundTripper
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+//Middleware wraps the sending of each request, it can change the request, the response or not call next at all.
+//The endpoint name of the request is available with EndpointFromContext(req.Context()).
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use - add middleware around the sending of requests, the first middleware added sees the request first
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+//WithMiddleware add middleware around the sending of requests, the first middleware given sees the request first
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.Use(middlewares...)
+	}
+}
+
+//roundTrip send the request through the middleware to the http client, each retry is sent through it again
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	next := RoundTripFunc(c.send)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		next = c.middlewares[i](next)
+	}
+	resp, err := next(req)
+	// A response made by a middleware, e.g. FaultMiddleware, may not have a body
+	if resp != nil && resp.Body == nil {
+		resp.Body = http.NoBody
+	}
+	return resp, err
+}
+
+//send send the request with the HTTP client, wrapping the body when the context has a body wrapper, e.g. to report upload progress
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if wrap := bodyWrapperFromContext(req.Context()); wrap != nil && req.Body != nil && req.Body != http.NoBody {
+		req.Body = wrap(req.Body)
+	}
+	return c.httpClient.Do(req)
+}
+
+//HeaderMiddleware set a header on every request, e.g. a tracing or proxy header
+func HeaderMiddleware(name string, value string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set(name, value)
+			return next(req)
+		}
+	}
+}
+
+//AuditRecord the details of a request that has been sent
+type AuditRecord struct {
+	Endpoint   string
+	Method     string
+	URL        string
+	StatusCode int // 0 when there was no response
+	Duration   time.Duration
+	Err        error
+}
+
+//AuditMiddleware call record after every request has been sent, e.g. to keep an audit log of changes
+func AuditMiddleware(record func(*AuditRecord)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			audit := &AuditRecord{
+				Endpoint: EndpointFromContext(req.Context()),
+				Method:   req.Method,
+				URL:      req.URL.String(),
+				Duration: time.Since(start),
+				Err:      err,
+			}
+			if resp != nil {
+				audit.StatusCode = resp.StatusCode
+			}
+			record(audit)
+			return resp, err
+		}
+	}
+}
+
+//FaultMiddleware call fault before every request, when it returns a response or an error the request isn't sent and they are used instead.
+//It is useful for testing how an application copes with errors from Twitch.
+func FaultMiddleware(fault func(req *http.Request) (*http.Response, error)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := fault(req)
+			if resp != nil || err != nil {
+				return resp, err
+			}
+			return next(req)
+		}
+	}
+}
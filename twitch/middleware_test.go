@@ -0,0 +1,181 @@
+package twitch
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(200, `{"_id":"1234"}`))
+
+	calls := []string{}
+	trace := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" "+EndpointFromContext(req.Context()))
+				resp, err := next(req)
+				calls = append(calls, name+" "+resp.Status)
+				return resp, err
+			}
+		}
+	}
+
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithMiddleware(trace("first")))
+	client.Use(trace("second"))
+
+	_, errorOutput := client.GetChannel()
+	if errorOutput != nil {
+		t.Errorf("GetChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+
+	expected := "first GetChannel,second GetChannel,second 200,first 200"
+	if strings.Join(calls, ",") != expected {
+		t.Errorf("The middleware was not called in order: %v", calls)
+	}
+}
+
+func TestMiddlewareRetries(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(503, `{"error":"Service Unavailable","status":503,"message":""}`))
+
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	records := []*AuditRecord{}
+	client := NewClient(&OAuthConfig{}, &http.Client{},
+		WithRetryPolicy(policy),
+		WithMiddleware(AuditMiddleware(func(record *AuditRecord) {
+			records = append(records, record)
+		})),
+	)
+
+	client.GetChannel()
+
+	if len(records) != 3 {
+		t.Errorf("The middleware did not see every attempt: %d", len(records))
+	}
+}
+
+func TestHeaderMiddleware(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Trace-Id") != "abc123" {
+				t.Errorf("HeaderMiddleware the header was not set: %s", req.Header.Get("X-Trace-Id"))
+			}
+			return httpmock.NewStringResponse(200, `{"_id":"1234"}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithMiddleware(HeaderMiddleware("X-Trace-Id", "abc123")))
+
+	_, errorOutput := client.GetChannel()
+	if errorOutput != nil {
+		t.Errorf("GetChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("DELETE", "https://api.twitch.tv/kraken/users/1/blocks/2",
+		httpmock.NewStringResponder(204, ""))
+
+	var record *AuditRecord
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithMiddleware(AuditMiddleware(func(r *AuditRecord) {
+		record = r
+	})))
+
+	_, errorOutput := client.UnblockUser(&UnblockUserInput{UserID: 1, TargetUserID: 2})
+	if errorOutput != nil {
+		t.Errorf("UnblockUser errorOutput should have been nil: %+v", errorOutput)
+	}
+
+	if record == nil {
+		t.Fatal("AuditMiddleware the record was not made")
+	}
+	if record.Endpoint != "UnblockUser" {
+		t.Errorf("AuditMiddleware the endpoint was not UnblockUser: %s", record.Endpoint)
+	}
+	if record.Method != "DELETE" {
+		t.Errorf("AuditMiddleware the method was not DELETE: %s", record.Method)
+	}
+	if record.URL != "https://api.twitch.tv/kraken/users/1/blocks/2" {
+		t.Errorf("AuditMiddleware the URL was not correct: %s", record.URL)
+	}
+	if record.StatusCode != 204 {
+		t.Errorf("AuditMiddleware the status code was not 204: %d", record.StatusCode)
+	}
+	if record.Err != nil {
+		t.Errorf("AuditMiddleware the error should have been nil: %s", record.Err)
+	}
+}
+
+func TestFaultMiddleware(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(200, `{"_id":"1234"}`))
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","name":"dallas"}`))
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/5678",
+		httpmock.NewStringResponder(200, `{"_id":"5678","name":"sent"}`))
+
+	fault := errors.New("connection reset")
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithMiddleware(FaultMiddleware(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case EndpointFromContext(req.Context()) == "GetChannel":
+			return nil, fault
+		case req.URL.Path == "/kraken/channels/1234":
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"_id":"1234","name":"injected"}`)),
+			}, nil
+		case req.URL.Path == "/kraken/channels/4321":
+			return &http.Response{StatusCode: 503, Header: http.Header{}}, nil
+		}
+		return nil, nil
+	})))
+
+	_, errorOutput := client.GetChannel()
+	if errorOutput == nil || !errors.Is(errorOutput.Err(), fault) {
+		t.Errorf("GetChannel the injected fault was not returned: %+v", errorOutput)
+	}
+
+	output, errorOutput := client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+	if errorOutput != nil || output.Name != "injected" {
+		t.Errorf("GetChannelByID the injected response was not returned: %+v %+v", output, errorOutput)
+	}
+
+	_, errorOutput = client.GetChannelByID(&GetChannelByIDInput{ChannelID: 4321})
+	if errorOutput == nil || !errors.Is(errorOutput.Err(), ErrServer) {
+		t.Errorf("GetChannelByID the injected response without a body was not returned: %+v", errorOutput)
+	}
+	if httpmock.GetTotalCallCount() != 0 {
+		t.Errorf("FaultMiddleware the requests should not have been sent: %d", httpmock.GetTotalCallCount())
+	}
+
+	output, errorOutput = client.GetChannelByID(&GetChannelByIDInput{ChannelID: 5678})
+	if errorOutput != nil || output.Name != "sent" {
+		t.Errorf("GetChannelByID the fault should not have been injected: %+v %+v", output, errorOutput)
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("FaultMiddleware the request without a fault was not sent: %d", httpmock.GetTotalCallCount())
+	}
+}
@@ -0,0 +1,321 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta tokens are refreshed this long before they actually expire
+const tokenExpiryDelta = time.Minute
+
+//Token an OAuth access token and the details needed to refresh it
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresIn    int64     `json:"expires_in,omitempty"`
+	Scopes       []string  `json:"scope,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+//TokenSource supplies the access token used to authorize requests, implementations must be safe for concurrent use
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+//TokenRefresher a TokenSource that can replace a token Twitch rejected with a 401
+type TokenRefresher interface {
+	TokenSource
+	Refresh(ctx context.Context, rejected *Token) (*Token, error)
+}
+
+//RefreshTokenInput the inputs used with the refresh token endpoint
+type RefreshTokenInput struct {
+	RefreshToken string
+}
+
+//ValidateTokenInput the inputs used with the validate token endpoint
+type ValidateTokenInput struct {
+	AccessToken string
+}
+
+//ValidateTokenOutput the details of a valid access token
+type ValidateTokenOutput struct {
+	ClientID  string   `json:"client_id"`
+	Login     string   `json:"login"`
+	Scopes    []string `json:"scopes"`
+	UserID    string   `json:"user_id"`
+	ExpiresIn int64    `json:"expires_in"`
+}
+
+//RevokeTokenInput the inputs used with the revoke token endpoint
+type RevokeTokenInput struct {
+	AccessToken string
+}
+
+//RevokeTokenOutput currently the output is empty
+type RevokeTokenOutput struct{}
+
+//staticTokenSource a token source that always returns the same token
+type staticTokenSource struct {
+	token *Token
+}
+
+//StaticTokenSource a token source for an access token that is never refreshed
+func StaticTokenSource(accessToken string) TokenSource {
+	return &staticTokenSource{token: &Token{AccessToken: accessToken}}
+}
+
+// Token - the static token
+func (s *staticTokenSource) Token(ctx context.Context) (*Token, error) {
+	return s.token, nil
+}
+
+// SetTokenSource - set the source of the access tokens used to authorize requests instead of OAuthConfig.AccessToken
+func (c *Client) SetTokenSource(tokenSource TokenSource) {
+	c.tokenSource = tokenSource
+}
+
+// RefreshToken - Exchange a refresh token for a new access token
+func (c *Client) RefreshToken(input *RefreshTokenInput) (*Token, *ErrorOutput) {
+	return c.RefreshTokenWithContext(context.Background(), input)
+}
+
+// RefreshTokenWithContext - the same as RefreshToken but the request is bound to the context
+func (c *Client) RefreshTokenWithContext(ctx context.Context, input *RefreshTokenInput) (*Token, *ErrorOutput) {
+	params := map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": input.RefreshToken,
+		"client_id":     c.oauthConfig.ClientID,
+		"client_secret": c.oauthConfig.ClientSecret,
+	}
+	return c.requestToken(withEndpoint(ctx, "RefreshToken"), params)
+}
+
+// ValidateToken - Check an access token is still valid and get the details of it
+func (c *Client) ValidateToken(input *ValidateTokenInput) (*ValidateTokenOutput, *ErrorOutput) {
+	return c.ValidateTokenWithContext(context.Background(), input)
+}
+
+// ValidateTokenWithContext - the same as ValidateToken but the request is bound to the context
+func (c *Client) ValidateTokenWithContext(ctx context.Context, input *ValidateTokenInput) (*ValidateTokenOutput, *ErrorOutput) {
+	output := new(ValidateTokenOutput)
+	req := c.createOAuthRequest("GET", "validate", nil)
+	req.Header.Set("Authorization", "OAuth "+input.AccessToken)
+	errorOutput := c.performRequest(withEndpoint(ctx, "ValidateToken"), req, output)
+	return output, errorOutput
+}
+
+// RevokeToken - Revoke an access token, e.g. when a user logs out
+func (c *Client) RevokeToken(input *RevokeTokenInput) (*RevokeTokenOutput, *ErrorOutput) {
+	return c.RevokeTokenWithContext(context.Background(), input)
+}
+
+// RevokeTokenWithContext - the same as RevokeToken but the request is bound to the context
+func (c *Client) RevokeTokenWithContext(ctx context.Context, input *RevokeTokenInput) (*RevokeTokenOutput, *ErrorOutput) {
+	params := map[string]string{
+		"client_id": c.oauthConfig.ClientID,
+		"token":     input.AccessToken,
+	}
+	output := new(RevokeTokenOutput)
+	errorOutput := c.performRequest(withEndpoint(ctx, "RevokeToken"), c.createOAuthRequest("POST", "revoke", params), output)
+	return output, errorOutput
+}
+
+//requestToken send a request to the token endpoint and work out when the new token expires
+func (c *Client) requestToken(ctx context.Context, params map[string]string) (*Token, *ErrorOutput) {
+	output := new(Token)
+	errorOutput := c.performRequest(ctx, c.createOAuthRequest("POST", "token", params), output)
+	if errorOutput == nil && output.ExpiresIn > 0 {
+		output.Expiry = c.now().Add(time.Duration(output.ExpiresIn) * time.Second)
+	}
+	return output, errorOutput
+}
+
+//createOAuthRequest create a request for the Twitch OAuth endpoints, POST params are form encoded
+func (c *Client) createOAuthRequest(method string, path string, params map[string]string) *http.Request {
+	data := url.Values{}
+	for key, val := range params {
+		data.Set(key, val)
+	}
+
+	var req *http.Request
+	if method == "GET" {
+		req, _ = http.NewRequest(method, c.idURL+path, nil)
+		req.URL.RawQuery = data.Encode()
+	} else {
+		req, _ = http.NewRequest(method, c.idURL+path, bytes.NewBufferString(data.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Client-ID", c.oauthConfig.ClientID)
+	return req
+}
+
+//performAuthorizedRequest authorize the request with the token source, refreshing and replaying it once on a 401.
+//The token and scope lookups and the replay are traced inside the span of the call.
+func (c *Client) performAuthorizedRequest(ctx context.Context, req *http.Request, output interface{}) *ErrorOutput {
+	ctx, span := c.startSpan(ctx, EndpointFromContext(ctx), req)
+	resp, errorOutput := c.performAuthorizedCall(ctx, req, output)
+	endSpan(span, resp, errorOutput.Err())
+	return errorOutput
+}
+
+//performAuthorizedCall send the authorized request, returning the response to the last attempt when there was one
+func (c *Client) performAuthorizedCall(ctx context.Context, req *http.Request, output interface{}) (*http.Response, *ErrorOutput) {
+	internal := withoutResponseMetadata(ctx)
+	token, err := c.currentToken(internal)
+	if err != nil {
+		return nil, c.errorToOutput(err)
+	}
+	if err := c.checkScopes(internal, token); err != nil {
+		return nil, c.errorToOutput(err)
+	}
+	if c.tokenSource == nil {
+		return c.performCall(ctx, req, output)
+	}
+
+	req.Header.Set("Authorization", "OAuth "+token.AccessToken)
+	resp, errorOutput := c.performCall(ctx, req, output)
+
+	refresher, ok := c.tokenSource.(TokenRefresher)
+	if errorOutput == nil || !ok || !errors.Is(errorOutput.Err(), ErrUnauthorized) {
+		return resp, errorOutput
+	}
+
+	// The token was rejected, replay the request with a fresh one
+	token, err = refresher.Refresh(internal, token)
+	if err != nil {
+		return resp, c.errorToOutput(err)
+	}
+	if err := rewindRequest(req); err != nil {
+		return resp, c.errorToOutput(err)
+	}
+	req.Header.Set("Authorization", "OAuth "+token.AccessToken)
+	return c.performCall(ctx, req, output)
+}
+
+//currentToken the token from the token source, or the static one in OAuthConfig
+func (c *Client) currentToken(ctx context.Context) (*Token, error) {
+	if c.tokenSource == nil {
+		return &Token{AccessToken: c.oauthConfig.AccessToken}, nil
+	}
+	return c.tokenSource.Token(ctx)
+}
+
+//RefreshTokenSource a token source that refreshes the user access token when it expires or is rejected
+type RefreshTokenSource struct {
+	// OnRefresh is called with every new token, e.g. to persist the new refresh token. Set it before use.
+	OnRefresh func(*Token)
+
+	client *Client
+	mu     sync.Mutex
+	token  *Token
+}
+
+// NewRefreshTokenSource - create a token source starting with the token, refreshed using the client's OAuthConfig
+func (c *Client) NewRefreshTokenSource(token *Token) *RefreshTokenSource {
+	return &RefreshTokenSource{
+		client: c,
+		token:  token,
+	}
+}
+
+// Token - the current token, refreshed first if it has expired
+func (s *RefreshTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.token.Expiry.IsZero() && s.client.now().Add(tokenExpiryDelta).After(s.token.Expiry) {
+		return s.refresh(ctx)
+	}
+	return s.token, nil
+}
+
+// Refresh - replace the rejected token, unless another caller has already done so
+func (s *RefreshTokenSource) Refresh(ctx context.Context, rejected *Token) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rejected != nil && s.token.AccessToken != rejected.AccessToken {
+		return s.token, nil
+	}
+	return s.refresh(ctx)
+}
+
+// Validate - check the current token with Twitch, refreshing it if it is no longer valid
+func (s *RefreshTokenSource) Validate(ctx context.Context) (*ValidateTokenOutput, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	output, errorOutput := s.client.ValidateTokenWithContext(ctx, &ValidateTokenInput{AccessToken: token.AccessToken})
+	if errorOutput == nil {
+		return output, nil
+	}
+	if !errors.Is(errorOutput.Err(), ErrUnauthorized) {
+		return nil, errorOutput.Err()
+	}
+
+	token, err = s.Refresh(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	output, errorOutput = s.client.ValidateTokenWithContext(ctx, &ValidateTokenInput{AccessToken: token.AccessToken})
+	return output, errorOutput.Err()
+}
+
+// KeepValid - validate the token every interval until the context is done, Twitch expects this hourly
+func (s *RefreshTokenSource) KeepValid(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.Validate(ctx); err != nil && ctx.Err() == nil && !isTransientError(err) {
+				return err
+			}
+		}
+	}
+}
+
+//isTransientError whether the error is likely to go away by itself
+func isTransientError(err error) bool {
+	var transportError *TransportError
+	return errors.As(err, &transportError) || errors.Is(err, ErrServer) || errors.Is(err, ErrRateLimited)
+}
+
+// Revoke - revoke the current token with Twitch, e.g. when the user logs out
+func (s *RefreshTokenSource) Revoke(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, errorOutput := s.client.RevokeTokenWithContext(ctx, &RevokeTokenInput{AccessToken: s.token.AccessToken})
+	return errorOutput.Err()
+}
+
+//refresh exchange the refresh token for a new token, the lock must be held
+func (s *RefreshTokenSource) refresh(ctx context.Context) (*Token, error) {
+	if s.token.RefreshToken == "" {
+		return nil, errors.New("twitch: the token has no refresh token")
+	}
+	token, errorOutput := s.client.RefreshTokenWithContext(ctx, &RefreshTokenInput{RefreshToken: s.token.RefreshToken})
+	if errorOutput != nil {
+		return nil, errorOutput.Err()
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = s.token.RefreshToken
+	}
+	s.token = token
+	if s.OnRefresh != nil {
+		s.OnRefresh(token)
+	}
+	return token, nil
+}
@@ -0,0 +1,328 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//fakeTwitch a local stand-in for the Twitch OAuth and API endpoints
+type fakeTwitch struct {
+	mu          sync.Mutex
+	accessToken string
+	refreshes   int32
+	revoked     []string
+	forms       []map[string]string
+	devicePolls []string // the error messages returned when polling for a device token, success once empty
+}
+
+func (f *fakeTwitch) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		f.mu.Lock()
+		form := map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		f.forms = append(f.forms, form)
+		f.mu.Unlock()
+
+		invalid := ""
+		switch r.PostForm.Get("grant_type") {
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != "refresh-token" {
+				invalid = "Invalid refresh token"
+			}
+		case "authorization_code":
+			if r.PostForm.Get("code") != "auth-code" {
+				invalid = "Invalid authorization code"
+			}
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			f.mu.Lock()
+			if r.PostForm.Get("device_code") != "device-code" {
+				invalid = "invalid device code"
+			} else if len(f.devicePolls) > 0 {
+				invalid = f.devicePolls[0]
+				f.devicePolls = f.devicePolls[1:]
+			}
+			f.mu.Unlock()
+		case "client_credentials":
+			if r.PostForm.Get("client_secret") != "client-secret" {
+				invalid = "invalid client secret"
+			}
+		default:
+			invalid = "Invalid grant type"
+		}
+		if invalid != "" {
+			w.WriteHeader(400)
+			w.Write([]byte(`{"status":400,"message":"` + invalid + `"}`))
+			return
+		}
+		n := atomic.AddInt32(&f.refreshes, 1)
+		f.mu.Lock()
+		f.accessToken = fmt.Sprintf("access-token-%d", n)
+		token := f.accessToken
+		f.mu.Unlock()
+		w.Write([]byte(`{"access_token":"` + token + `","refresh_token":"refresh-token","expires_in":3600,"scope":["channel_read"],"token_type":"bearer"}`))
+	})
+	mux.HandleFunc("/oauth2/device", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		f.mu.Lock()
+		f.forms = append(f.forms, map[string]string{"client_id": r.PostForm.Get("client_id"), "scopes": r.PostForm.Get("scopes")})
+		f.mu.Unlock()
+		w.Write([]byte(`{"device_code":"device-code","expires_in":1800,"interval":1,"user_code":"ABCDEFGH","verification_uri":"https://www.twitch.tv/activate?device-code=ABCDEFGH"}`))
+	})
+	mux.HandleFunc("/oauth2/validate", func(w http.ResponseWriter, r *http.Request) {
+		if !f.authorized(r) {
+			w.WriteHeader(401)
+			w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+			return
+		}
+		w.Write([]byte(`{"client_id":"client-id","login":"dallas","scopes":["channel_read"],"user_id":"1234","expires_in":3600}`))
+	})
+	mux.HandleFunc("/oauth2/revoke", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		f.mu.Lock()
+		f.revoked = append(f.revoked, r.PostForm.Get("token"))
+		f.mu.Unlock()
+		w.WriteHeader(200)
+	})
+	mux.HandleFunc("/kraken/channel", func(w http.ResponseWriter, r *http.Request) {
+		if !f.authorized(r) {
+			w.WriteHeader(401)
+			w.Write([]byte(`{"error":"Unauthorized","status":401,"message":"invalid oauth token"}`))
+			return
+		}
+		w.Write([]byte(`{"_id":"1234","name":"dallas"}`))
+	})
+	return mux
+}
+
+func (f *fakeTwitch) authorized(r *http.Request) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return r.Header.Get("Authorization") == "OAuth "+f.accessToken
+}
+
+func newFakeTwitchClient(server *httptest.Server, options ...ClientOption) *Client {
+	options = append([]ClientOption{
+		WithAPIURL(server.URL + "/kraken/"),
+		WithIDURL(server.URL + "/oauth2/"),
+	}, options...)
+	return NewClient(&OAuthConfig{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+	}, server.Client(), options...)
+}
+
+func TestRefreshToken(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	now := time.Unix(1500000000, 0)
+	client := newFakeTwitchClient(server, WithClock(func() time.Time { return now }))
+
+	token, errorOutput := client.RefreshToken(&RefreshTokenInput{RefreshToken: "refresh-token"})
+
+	if errorOutput != nil {
+		t.Fatalf("RefreshToken errorOutput should have been nil: %+v", errorOutput)
+	}
+	if token.AccessToken != "access-token-1" {
+		t.Errorf("RefreshToken access token was not \"access-token-1\": %s", token.AccessToken)
+	}
+	if !token.Expiry.Equal(now.Add(time.Hour)) {
+		t.Errorf("RefreshToken expiry was not an hour from now: %s", token.Expiry)
+	}
+	form := fake.forms[0]
+	if form["grant_type"] != "refresh_token" || form["client_id"] != "client-id" || form["client_secret"] != "client-secret" {
+		t.Errorf("RefreshToken form was not correct: %+v", form)
+	}
+}
+
+func TestRefreshTokenInvalid(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+
+	_, errorOutput := client.RefreshToken(&RefreshTokenInput{RefreshToken: "wrong"})
+
+	if errorOutput == nil || errorOutput.Status != 400 {
+		t.Fatalf("RefreshTokenInvalid errorOutput should have been a 400: %+v", errorOutput)
+	}
+	if errorOutput.Message != "Invalid refresh token" {
+		t.Errorf("RefreshTokenInvalid error.Message was not \"Invalid refresh token\": %s", errorOutput.Message)
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	fake := &fakeTwitch{accessToken: "access-token"}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+
+	output, errorOutput := client.ValidateToken(&ValidateTokenInput{AccessToken: "access-token"})
+
+	if errorOutput != nil {
+		t.Fatalf("ValidateToken errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.Login != "dallas" || output.UserID != "1234" || output.ExpiresIn != 3600 {
+		t.Errorf("ValidateToken output was not correct: %+v", output)
+	}
+
+	_, errorOutput = client.ValidateToken(&ValidateTokenInput{AccessToken: "expired"})
+	if !errors.Is(errorOutput.Err(), ErrUnauthorized) {
+		t.Errorf("ValidateToken expired token error was not ErrUnauthorized: %v", errorOutput.Err())
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+
+	_, errorOutput := client.RevokeToken(&RevokeTokenInput{AccessToken: "access-token"})
+
+	if errorOutput != nil {
+		t.Fatalf("RevokeToken errorOutput should have been nil: %+v", errorOutput)
+	}
+	if len(fake.revoked) != 1 || fake.revoked[0] != "access-token" {
+		t.Errorf("RevokeToken the token was not revoked: %+v", fake.revoked)
+	}
+}
+
+func TestRefreshTokenSourceExpired(t *testing.T) {
+	fake := &fakeTwitch{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	now := time.Unix(1500000000, 0)
+	client := newFakeTwitchClient(server, WithClock(func() time.Time { return now }))
+	source := client.NewRefreshTokenSource(&Token{
+		AccessToken:  "expired",
+		RefreshToken: "refresh-token",
+		Expiry:       now.Add(30 * time.Second),
+	})
+	refreshed := []*Token{}
+	source.OnRefresh = func(token *Token) {
+		refreshed = append(refreshed, token)
+	}
+	client.SetTokenSource(source)
+
+	output, errorOutput := client.GetChannel()
+
+	if errorOutput != nil {
+		t.Fatalf("RefreshTokenSourceExpired errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.ID != "1234" {
+		t.Errorf("RefreshTokenSourceExpired the ID was not 1234: %s", output.ID)
+	}
+	if len(refreshed) != 1 || refreshed[0].AccessToken != "access-token-1" {
+		t.Errorf("RefreshTokenSourceExpired OnRefresh was not called with the new token: %+v", refreshed)
+	}
+}
+
+func TestRefreshTokenSourceReplayOnUnauthorized(t *testing.T) {
+	fake := &fakeTwitch{accessToken: "access-token-0"}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+	client.SetTokenSource(client.NewRefreshTokenSource(&Token{
+		AccessToken:  "revoked",
+		RefreshToken: "refresh-token",
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output, errorOutput := client.GetChannel()
+			if errorOutput != nil {
+				t.Errorf("RefreshTokenSourceReplayOnUnauthorized errorOutput should have been nil: %+v", errorOutput)
+				return
+			}
+			if output.ID != "1234" {
+				t.Errorf("RefreshTokenSourceReplayOnUnauthorized the ID was not 1234: %s", output.ID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&fake.refreshes) != 1 {
+		t.Errorf("RefreshTokenSourceReplayOnUnauthorized the token should have been refreshed once: %d", fake.refreshes)
+	}
+}
+
+func TestRefreshTokenSourceWithoutRefreshToken(t *testing.T) {
+	fake := &fakeTwitch{accessToken: "access-token-0"}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+	client.SetTokenSource(client.NewRefreshTokenSource(&Token{AccessToken: "revoked"}))
+
+	_, errorOutput := client.GetChannel()
+
+	if errorOutput == nil {
+		t.Fatalf("RefreshTokenSourceWithoutRefreshToken errorOutput shouldn't have been nil")
+	}
+	if atomic.LoadInt32(&fake.refreshes) != 0 {
+		t.Errorf("RefreshTokenSourceWithoutRefreshToken the token should not have been refreshed: %d", fake.refreshes)
+	}
+}
+
+func TestRefreshTokenSourceValidateAndRevoke(t *testing.T) {
+	fake := &fakeTwitch{accessToken: "access-token-0"}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+	source := client.NewRefreshTokenSource(&Token{
+		AccessToken:  "revoked",
+		RefreshToken: "refresh-token",
+	})
+
+	output, err := source.Validate(context.Background())
+
+	if err != nil {
+		t.Fatalf("RefreshTokenSourceValidateAndRevoke err should have been nil: %v", err)
+	}
+	if output.Login != "dallas" {
+		t.Errorf("RefreshTokenSourceValidateAndRevoke login was not \"dallas\": %s", output.Login)
+	}
+
+	if err := source.Revoke(context.Background()); err != nil {
+		t.Fatalf("RefreshTokenSourceValidateAndRevoke revoke err should have been nil: %v", err)
+	}
+	if len(fake.revoked) != 1 || fake.revoked[0] != "access-token-1" {
+		t.Errorf("RefreshTokenSourceValidateAndRevoke the refreshed token was not revoked: %+v", fake.revoked)
+	}
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	fake := &fakeTwitch{accessToken: "static-token"}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server, WithTokenSource(StaticTokenSource("static-token")))
+
+	_, errorOutput := client.GetChannel()
+
+	if errorOutput != nil {
+		t.Errorf("StaticTokenSource errorOutput should have been nil: %+v", errorOutput)
+	}
+}
@@ -0,0 +1,95 @@
+package twitch
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+//ClientOption changes the default configuration of a Client created with NewClient
+type ClientOption func(*Client)
+
+//WithAPIURL the base URL of the Twitch API, e.g. a local stand-in or staging proxy
+func WithAPIURL(apiURL string) ClientOption {
+	return func(c *Client) {
+		c.apiURL = withTrailingSlash(apiURL)
+	}
+}
+
+//WithUploadURL the base URL used for video uploads
+func WithUploadURL(uploadURL string) ClientOption {
+	return func(c *Client) {
+		c.uploadURL = withTrailingSlash(uploadURL)
+	}
+}
+
+//WithAPIVersion the version sent in the Accept header of API requests
+func WithAPIVersion(version int) ClientOption {
+	return func(c *Client) {
+		c.apiVersion = version
+	}
+}
+
+//WithUploadVersion the version sent in the Accept header of upload requests
+func WithUploadVersion(version int) ClientOption {
+	return func(c *Client) {
+		c.uploadVersion = version
+	}
+}
+
+//WithUserAgent the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+//WithHTTPClient the http client used to send requests, replacing the one given to NewClient
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+//WithClock the function used to get the current time
+func WithClock(now func() time.Time) ClientOption {
+	return func(c *Client) {
+		c.now = now
+	}
+}
+
+//WithRetryPolicy the policy used to retry failed requests
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+//WithRateLimiter the rate limiter requests wait on before being sent
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+//WithIDURL the base URL of the Twitch OAuth endpoints
+func WithIDURL(idURL string) ClientOption {
+	return func(c *Client) {
+		c.idURL = withTrailingSlash(idURL)
+	}
+}
+
+//WithTokenSource the source of the access tokens used to authorize requests instead of OAuthConfig.AccessToken
+func WithTokenSource(tokenSource TokenSource) ClientOption {
+	return func(c *Client) {
+		c.tokenSource = tokenSource
+	}
+}
+
+//withTrailingSlash paths are appended to the base URLs so they must end with a slash
+func withTrailingSlash(baseURL string) string {
+	if strings.HasSuffix(baseURL, "/") {
+		return baseURL
+	}
+	return baseURL + "/"
+}
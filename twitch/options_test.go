@@ -0,0 +1,106 @@
+package twitch
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	policy := DefaultRetryPolicy()
+	limiter := NewRateLimiter(800, time.Minute)
+	now := time.Unix(1500000000, 0)
+
+	client := NewClient(&OAuthConfig{}, nil,
+		WithAPIURL("http://localhost:8080/kraken"),
+		WithUploadURL("http://localhost:8081/"),
+		WithAPIVersion(3),
+		WithUploadVersion(2),
+		WithUserAgent("My App/1.0"),
+		WithHTTPClient(httpClient),
+		WithRetryPolicy(policy),
+		WithRateLimiter(limiter),
+		WithClock(func() time.Time { return now }),
+	)
+
+	if client.apiURL != "http://localhost:8080/kraken/" {
+		t.Errorf("client.apiURL was not correct: %s", client.apiURL)
+	}
+	if client.uploadURL != "http://localhost:8081/" {
+		t.Errorf("client.uploadURL was not correct: %s", client.uploadURL)
+	}
+	if client.apiVersion != 3 {
+		t.Errorf("client.apiVersion was not 3: %d", client.apiVersion)
+	}
+	if client.uploadVersion != 2 {
+		t.Errorf("client.uploadVersion was not 2: %d", client.uploadVersion)
+	}
+	if client.userAgent != "My App/1.0" {
+		t.Errorf("client.userAgent was not correct: %s", client.userAgent)
+	}
+	if client.httpClient != httpClient {
+		t.Errorf("client.httpClient was not correct: %+v", client.httpClient)
+	}
+	if client.retryPolicy != policy {
+		t.Errorf("client.retryPolicy was not correct: %+v", client.retryPolicy)
+	}
+	if client.rateLimiter != limiter {
+		t.Errorf("client.rateLimiter was not correct: %+v", client.rateLimiter)
+	}
+	if client.now() != now {
+		t.Errorf("client.now was not correct: %s", client.now())
+	}
+}
+
+func TestNewClientDefaultHTTPClient(t *testing.T) {
+	client := NewClient(&OAuthConfig{}, nil)
+
+	if client.httpClient != http.DefaultClient {
+		t.Errorf("client.httpClient was not the default client: %+v", client.httpClient)
+	}
+}
+
+func TestClientOptionsRequests(t *testing.T) {
+	requests := []*http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&OAuthConfig{}, server.Client(),
+		WithAPIURL(server.URL+"/kraken"),
+		WithUploadURL(server.URL),
+		WithAPIVersion(3),
+		WithUserAgent("My App/1.0"),
+	)
+
+	client.GetRoot()
+	client.UploadVideoPart(&UploadVideoPartInput{
+		VideoID: "v1234",
+		Token:   "upload-token",
+		Part:    1,
+		Body:    bytes.NewBufferString("foobar"),
+	})
+
+	if len(requests) != 2 {
+		t.Fatalf("ClientOptionsRequests 2 requests were not received: %d", len(requests))
+	}
+	if requests[0].URL.Path != "/kraken/" {
+		t.Errorf("ClientOptionsRequests API path was not /kraken/: %s", requests[0].URL.Path)
+	}
+	if requests[0].Header.Get("Accept") != "application/vnd.twitchtv.v3+json" {
+		t.Errorf("ClientOptionsRequests Accept header was not \"application/vnd.twitchtv.v3+json\": %s", requests[0].Header.Get("Accept"))
+	}
+	if requests[1].URL.Path != "/upload/1234" {
+		t.Errorf("ClientOptionsRequests upload path was not /upload/1234: %s", requests[1].URL.Path)
+	}
+	for _, req := range requests {
+		if req.Header.Get("User-Agent") != "My App/1.0" {
+			t.Errorf("ClientOptionsRequests User-Agent header was not \"My App/1.0\": %s", req.Header.Get("User-Agent"))
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package twitch
+
+// maxPageLimit the largest page size the list endpoints allow
+const maxPageLimit = 100
+
+//pageLimit the page size used when walking pages, the largest allowed size when none is set
+func pageLimit(limit int64) int64 {
+	if limit <= 0 || limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
+//lastOffsetPage whether an offset based page is the last one
+func lastOffsetPage(count int, limit int64, offset int64, total int64) bool {
+	return count == 0 || int64(count) < limit || (total > 0 && offset >= total)
+}
@@ -0,0 +1,115 @@
+package twitch
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//RateLimiter a token bucket that adapts to the Ratelimit-* headers returned by Twitch.
+//Twitch limits requests per Client-ID, so Clients sharing a Client-ID should share a RateLimiter.
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    float64
+	interval time.Duration
+	tokens   float64
+	last     time.Time
+	resetAt  time.Time
+	now      func() time.Time
+}
+
+//NewRateLimiter create a bucket that allows limit requests per interval, e.g. 800 per minute
+func NewRateLimiter(limit int, interval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:    float64(limit),
+		interval: interval,
+		tokens:   float64(limit),
+		now:      time.Now,
+	}
+}
+
+// SetRateLimiter - set the rate limiter requests wait on before being sent, nil disables rate limiting
+func (c *Client) SetRateLimiter(limiter *RateLimiter) {
+	c.rateLimiter = limiter
+}
+
+// Wait - block until a request can be made or the context is done
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := r.reserve()
+		if delay <= 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Remaining - the number of requests that can currently be made without waiting
+func (r *RateLimiter) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refill(r.now())
+	return int(r.tokens)
+}
+
+//reserve take a token if one is available, otherwise return how long to wait before trying again
+func (r *RateLimiter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.refill(now)
+	if now.Before(r.resetAt) && r.tokens < 1 {
+		return r.resetAt.Sub(now)
+	}
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0
+	}
+	if r.limit <= 0 || r.interval <= 0 {
+		return 0
+	}
+	return time.Duration((1 - r.tokens) * float64(r.interval) / r.limit)
+}
+
+//refill add the tokens earned since the last refill, the bucket is full again after a reset
+func (r *RateLimiter) refill(now time.Time) {
+	if !r.resetAt.IsZero() && !now.Before(r.resetAt) {
+		r.tokens = r.limit
+		r.resetAt = time.Time{}
+	}
+	if !r.last.IsZero() && r.interval > 0 {
+		r.tokens += now.Sub(r.last).Seconds() * r.limit / r.interval.Seconds()
+	}
+	r.tokens = math.Min(r.tokens, r.limit)
+	r.last = now
+}
+
+//update adapt the bucket to the limits reported by Twitch in the response headers
+func (r *RateLimiter) update(statusCode int, header http.Header) {
+	limit, limitErr := strconv.Atoi(header.Get("Ratelimit-Limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("Ratelimit-Remaining"))
+	reset, resetErr := strconv.ParseInt(header.Get("Ratelimit-Reset"), 10, 64)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refill(r.now())
+	if limitErr == nil && limit > 0 {
+		r.limit = float64(limit)
+	}
+	if remainingErr == nil {
+		r.tokens = math.Min(r.tokens, float64(remaining))
+	}
+	if statusCode == http.StatusTooManyRequests {
+		r.tokens = 0
+	}
+	if resetErr == nil && r.tokens < 1 {
+		r.resetAt = time.Unix(reset, 0)
+	}
+}
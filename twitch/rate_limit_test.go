@@ -0,0 +1,123 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func newTestRateLimiter(limit int, interval time.Duration, now *time.Time) *RateLimiter {
+	limiter := NewRateLimiter(limit, interval)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	limiter := newTestRateLimiter(2, time.Second, &now)
+
+	if delay := limiter.reserve(); delay != 0 {
+		t.Errorf("RateLimiterTokenBucket first request should not wait: %s", delay)
+	}
+	if delay := limiter.reserve(); delay != 0 {
+		t.Errorf("RateLimiterTokenBucket second request should not wait: %s", delay)
+	}
+	if delay := limiter.reserve(); delay != 500*time.Millisecond {
+		t.Errorf("RateLimiterTokenBucket third request should wait 500ms: %s", delay)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if delay := limiter.reserve(); delay != 0 {
+		t.Errorf("RateLimiterTokenBucket request after refill should not wait: %s", delay)
+	}
+	if limiter.Remaining() != 0 {
+		t.Errorf("RateLimiterTokenBucket remaining was not 0: %d", limiter.Remaining())
+	}
+}
+
+func TestRateLimiterHeaders(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	limiter := newTestRateLimiter(800, time.Minute, &now)
+
+	header := http.Header{}
+	header.Set("Ratelimit-Limit", "30")
+	header.Set("Ratelimit-Remaining", "0")
+	header.Set("Ratelimit-Reset", strconv.FormatInt(now.Add(20*time.Second).Unix(), 10))
+	limiter.update(200, header)
+
+	if delay := limiter.reserve(); delay != 20*time.Second {
+		t.Errorf("RateLimiterHeaders request should wait until the reset: %s", delay)
+	}
+
+	now = now.Add(20 * time.Second)
+	if limiter.Remaining() != 30 {
+		t.Errorf("RateLimiterHeaders the bucket was not refilled to the new limit after the reset: %d", limiter.Remaining())
+	}
+}
+
+func TestRateLimiterTooManyRequests(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	limiter := newTestRateLimiter(800, time.Minute, &now)
+
+	header := http.Header{}
+	header.Set("Ratelimit-Reset", strconv.FormatInt(now.Add(5*time.Second).Unix(), 10))
+	limiter.update(429, header)
+
+	if delay := limiter.reserve(); delay != 5*time.Second {
+		t.Errorf("RateLimiterTooManyRequests request should wait until the reset: %s", delay)
+	}
+}
+
+func TestRateLimiterWaitContext(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Hour)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("RateLimiterWaitContext wait was not cut short by the context: %v", err)
+	}
+}
+
+func TestRateLimiterSharedBetweenClients(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"token":{"valid":true}}`)
+			resp.Header.Set("Ratelimit-Limit", "800")
+			resp.Header.Set("Ratelimit-Remaining", "0")
+			resp.Header.Set("Ratelimit-Reset", reset)
+			return resp, nil
+		})
+
+	limiter := NewRateLimiter(800, time.Minute)
+	first := NewClient(&OAuthConfig{ClientID: "client-id"}, &http.Client{})
+	first.SetRateLimiter(limiter)
+	second := NewClient(&OAuthConfig{ClientID: "client-id"}, &http.Client{})
+	second.SetRateLimiter(limiter)
+
+	if _, errorOutput := first.GetRoot(); errorOutput != nil {
+		t.Fatalf("RateLimiterSharedBetweenClients first request errorOutput should have been nil: %+v", errorOutput)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, errorOutput := second.GetRootWithContext(ctx)
+
+	if !errors.Is(errorOutput.Err(), context.DeadlineExceeded) {
+		t.Errorf("RateLimiterSharedBetweenClients second request should have waited for the reset: %v", errorOutput.Err())
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("RateLimiterSharedBetweenClients only the first request should have been sent: %d", httpmock.GetTotalCallCount())
+	}
+}
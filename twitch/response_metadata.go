@@ -0,0 +1,71 @@
+package twitch
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// requestIDHeaders the headers Twitch identifies a request with, in order of preference
+var requestIDHeaders = []string{"Twitch-Trace-Id", "X-Request-Id"}
+
+//ResponseMetadata the details of the response to a call, the status code is 0 when there was no response
+type ResponseMetadata struct {
+	Endpoint           string
+	StatusCode         int
+	Header             http.Header
+	RequestID          string
+	ETag               string
+	RateLimitLimit     int
+	RateLimitRemaining int
+	RateLimitReset     time.Time
+	Retries            int
+}
+
+//responseMetadataContextKey the context key of the ResponseMetadata to fill in
+type responseMetadataContextKey struct{}
+
+// WithResponseMetadata - fill in metadata with the details of the response once a call with the returned context has finished
+func WithResponseMetadata(ctx context.Context, metadata *ResponseMetadata) context.Context {
+	return context.WithValue(ctx, responseMetadataContextKey{}, metadata)
+}
+
+//withoutResponseMetadata a context for the requests a call makes along the way, e.g. to refresh the token,
+//so only the response to the call itself fills in the ResponseMetadata
+func withoutResponseMetadata(ctx context.Context) context.Context {
+	if ctx.Value(responseMetadataContextKey{}) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, responseMetadataContextKey{}, (*ResponseMetadata)(nil))
+}
+
+//setResponseMetadata fill in the ResponseMetadata of the context, if there is one
+func setResponseMetadata(ctx context.Context, resp *http.Response, retries int) {
+	metadata, _ := ctx.Value(responseMetadataContextKey{}).(*ResponseMetadata)
+	if metadata == nil {
+		return
+	}
+
+	*metadata = ResponseMetadata{
+		Endpoint: EndpointFromContext(ctx),
+		Retries:  retries,
+	}
+	if resp == nil {
+		return
+	}
+	metadata.StatusCode = resp.StatusCode
+	metadata.Header = resp.Header
+	metadata.ETag = resp.Header.Get("ETag")
+	for _, name := range requestIDHeaders {
+		if id := resp.Header.Get(name); id != "" {
+			metadata.RequestID = id
+			break
+		}
+	}
+	metadata.RateLimitLimit, _ = strconv.Atoi(resp.Header.Get("Ratelimit-Limit"))
+	metadata.RateLimitRemaining, _ = strconv.Atoi(resp.Header.Get("Ratelimit-Remaining"))
+	if reset, err := strconv.ParseInt(resp.Header.Get("Ratelimit-Reset"), 10, 64); err == nil {
+		metadata.RateLimitReset = time.Unix(reset, 0)
+	}
+}
@@ -0,0 +1,112 @@
+package twitch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestWithResponseMetadata(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, `{"_id":"1234"}`)
+			resp.Header.Set("ETag", `"abc123"`)
+			resp.Header.Set("Twitch-Trace-Id", "trace-id")
+			resp.Header.Set("Ratelimit-Limit", "800")
+			resp.Header.Set("Ratelimit-Remaining", "799")
+			resp.Header.Set("Ratelimit-Reset", "1500000060")
+			return resp, nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	var metadata ResponseMetadata
+	output, errorOutput := client.GetChannelWithContext(WithResponseMetadata(context.Background(), &metadata))
+	if errorOutput != nil {
+		t.Errorf("GetChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.ID != "1234" {
+		t.Errorf("GetChannel the channel id was not 1234: %s", output.ID)
+	}
+
+	if metadata.Endpoint != "GetChannel" {
+		t.Errorf("ResponseMetadata the endpoint was not GetChannel: %s", metadata.Endpoint)
+	}
+	if metadata.StatusCode != 200 {
+		t.Errorf("ResponseMetadata the status code was not 200: %d", metadata.StatusCode)
+	}
+	if metadata.ETag != `"abc123"` {
+		t.Errorf("ResponseMetadata the ETag was not correct: %s", metadata.ETag)
+	}
+	if metadata.RequestID != "trace-id" {
+		t.Errorf("ResponseMetadata the request ID was not trace-id: %s", metadata.RequestID)
+	}
+	if metadata.RateLimitLimit != 800 || metadata.RateLimitRemaining != 799 {
+		t.Errorf("ResponseMetadata the rate limit was not correct: %d %d", metadata.RateLimitLimit, metadata.RateLimitRemaining)
+	}
+	if !metadata.RateLimitReset.Equal(time.Unix(1500000060, 0)) {
+		t.Errorf("ResponseMetadata the rate limit reset was not correct: %s", metadata.RateLimitReset)
+	}
+	if metadata.Header.Get("Ratelimit-Limit") != "800" {
+		t.Errorf("ResponseMetadata the header was not set: %+v", metadata.Header)
+	}
+}
+
+func TestWithResponseMetadataError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(503, `{"error":"Service Unavailable","status":503,"message":""}`))
+
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithRetryPolicy(policy))
+
+	var metadata ResponseMetadata
+	_, errorOutput := client.GetChannelByIDWithContext(WithResponseMetadata(context.Background(), &metadata), &GetChannelByIDInput{ChannelID: 1234})
+	if errorOutput == nil {
+		t.Error("GetChannelByID errorOutput should not have been nil")
+	}
+
+	if metadata.StatusCode != 503 {
+		t.Errorf("ResponseMetadata the status code was not 503: %d", metadata.StatusCode)
+	}
+	if metadata.Retries != 2 {
+		t.Errorf("ResponseMetadata the retries was not 2: %d", metadata.Retries)
+	}
+}
+
+func TestWithResponseMetadataRefresh(t *testing.T) {
+	fake := &fakeTwitch{accessToken: "access-token-0"}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newFakeTwitchClient(server)
+	client.SetTokenSource(client.NewRefreshTokenSource(&Token{AccessToken: "revoked", RefreshToken: "refresh-token"}))
+
+	var metadata ResponseMetadata
+	_, errorOutput := client.GetChannelWithContext(WithResponseMetadata(context.Background(), &metadata))
+	if errorOutput != nil {
+		t.Errorf("GetChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+	if metadata.Endpoint != "GetChannel" || metadata.StatusCode != 200 {
+		t.Errorf("ResponseMetadata was not for the replayed GetChannel: %s %d", metadata.Endpoint, metadata.StatusCode)
+	}
+
+	client.SetTokenSource(client.NewRefreshTokenSource(&Token{AccessToken: "revoked", RefreshToken: "revoked"}))
+	_, errorOutput = client.GetChannelWithContext(WithResponseMetadata(context.Background(), &metadata))
+	if errorOutput == nil {
+		t.Error("GetChannel errorOutput should not have been nil")
+	}
+	if metadata.Endpoint != "GetChannel" || metadata.StatusCode != 401 {
+		t.Errorf("ResponseMetadata was overwritten by the failed refresh: %s %d", metadata.Endpoint, metadata.StatusCode)
+	}
+}
@@ -0,0 +1,153 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//RetryPolicy controls when and how often a failed request is sent again
+type RetryPolicy struct {
+	MaxAttempts       int           // Total number of attempts including the first one. 0 or 1 disables retries.
+	BaseDelay         time.Duration // The delay before the first retry, doubled for every following retry
+	MaxDelay          time.Duration // The upper bound of the delay between two attempts
+	Jitter            float64       // Fraction (0-1) of each delay that is randomised
+	RetryableStatuses []int         // Response status codes that are retried
+	RetryableMethods  []string      // HTTP methods that are retried, add DELETE or POST to opt in to calls with side effects
+}
+
+//DefaultRetryPolicy a policy retrying GET, HEAD and PUT requests on 429, 5xx and transport errors.
+//DELETE isn't retried as endpoints such as ResetStreamKey change something every time they are called.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.2,
+		RetryableStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableMethods: []string{"GET", "HEAD", "PUT"},
+	}
+}
+
+// SetRetryPolicy - set the policy used to retry failed requests, nil disables retries
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+//shouldRetry whether the request should be attempted again after the error
+func (p *RetryPolicy) shouldRetry(ctx context.Context, req *http.Request, attempt int, errorOutput *ErrorOutput) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if !p.retryableMethod(req.Method) {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	return p.retryableError(errorOutput)
+}
+
+//retryableError whether the error is a retryable status or a transport error
+func (p *RetryPolicy) retryableError(errorOutput *ErrorOutput) bool {
+	var apiError *APIError
+	var transportError *TransportError
+	switch {
+	case errors.As(errorOutput.Err(), &apiError):
+		return p.retryableStatus(apiError.StatusCode)
+	case errors.As(errorOutput.Err(), &transportError):
+		return true
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryableMethod(method string) bool {
+	for _, m := range p.RetryableMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryableStatus(status int) bool {
+	for _, s := range p.RetryableStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+//delay how long to wait before the next attempt, a Retry-After header takes precedence but is kept within MaxDelay
+func (p *RetryPolicy) delay(attempt int, header http.Header, now time.Time) time.Duration {
+	if retryAfter, ok := parseRetryAfter(header, now); ok {
+		if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+			return p.MaxDelay
+		}
+		return retryAfter
+	}
+
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(attempt-1)))
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay <= 0) {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+//parseRetryAfter read the Retry-After header, in either seconds or HTTP date format
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+//rewindRequest get a fresh copy of the request body so it can be sent again
+func rewindRequest(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+//sleepContext wait for the delay, returning early with the error if the context is done
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,256 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetryTransientStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls < 3 {
+				return httpmock.NewStringResponse(503, `{"error":"Service Unavailable","status":503,"message":""}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{"_id":"1234","name":"dallas"}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	client.SetRetryPolicy(testRetryPolicy())
+
+	output, errorOutput := client.GetChannelByID(&GetChannelByIDInput{ChannelID: 1234})
+
+	if errorOutput != nil {
+		t.Fatalf("RetryTransientStatus errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.ID != "1234" {
+		t.Errorf("RetryTransientStatus the ID was not 1234: %s", output.ID)
+	}
+	if calls != 3 {
+		t.Errorf("RetryTransientStatus the request was not sent 3 times: %d", calls)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		httpmock.NewStringResponder(500, `{"error":"Internal Server Error","status":500,"message":"oops"}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	client.SetRetryPolicy(testRetryPolicy())
+
+	_, errorOutput := client.GetRoot()
+
+	if errorOutput == nil || errorOutput.Status != 500 {
+		t.Fatalf("RetryGivesUpAfterMaxAttempts errorOutput should have been a 500: %+v", errorOutput)
+	}
+	if httpmock.GetTotalCallCount() != 3 {
+		t.Errorf("RetryGivesUpAfterMaxAttempts the request was not sent 3 times: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestRetryNotRetryableStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		httpmock.NewStringResponder(404, `{"error":"Not Found","status":404,"message":""}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	client.SetRetryPolicy(testRetryPolicy())
+
+	client.GetRoot()
+
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("RetryNotRetryableStatus the request was not sent once: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestRetryTransportError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	calls := 0
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("connection reset by peer")
+			}
+			return httpmock.NewStringResponse(200, `{"token":{"valid":true}}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	client.SetRetryPolicy(testRetryPolicy())
+
+	output, errorOutput := client.GetRoot()
+
+	if errorOutput != nil {
+		t.Fatalf("RetryTransportError errorOutput should have been nil: %+v", errorOutput)
+	}
+	if output.Token.Valid != true {
+		t.Errorf("RetryTransportError token.valid was not true")
+	}
+}
+
+func TestRetryNonIdempotentMethod(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "https://api.twitch.tv/kraken/channel/1234/commercial",
+		httpmock.NewStringResponder(503, `{"error":"Service Unavailable","status":503,"message":""}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	client.SetRetryPolicy(testRetryPolicy())
+
+	client.StartChannelCommercial(&StartChannelCommercialInput{ChannelID: 1234, Length: 30})
+
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("RetryNonIdempotentMethod POST should not have been retried: %d", httpmock.GetTotalCallCount())
+	}
+
+	// Opt in to retrying POST requests
+	httpmock.Reset()
+	httpmock.RegisterResponder("POST", "https://api.twitch.tv/kraken/channel/1234/commercial",
+		httpmock.NewStringResponder(503, `{"error":"Service Unavailable","status":503,"message":""}`))
+	policy := testRetryPolicy()
+	policy.RetryableMethods = append(policy.RetryableMethods, "POST")
+	client.SetRetryPolicy(policy)
+
+	client.StartChannelCommercial(&StartChannelCommercialInput{ChannelID: 1234, Length: 30})
+
+	if httpmock.GetTotalCallCount() != 3 {
+		t.Errorf("RetryNonIdempotentMethod opted in POST was not sent 3 times: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestRetryUploadResendsBody(t *testing.T) {
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(502)
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"upload":{},"video":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&OAuthConfig{}, server.Client(), WithUploadURL(server.URL))
+	client.SetRetryPolicy(testRetryPolicy())
+
+	_, errorOutput := client.UploadVideoPart(&UploadVideoPartInput{
+		VideoID: "v1234",
+		Token:   "upload-token",
+		Part:    1,
+		Body:    bytes.NewBufferString("foobar"),
+	})
+
+	if errorOutput != nil {
+		t.Fatalf("RetryUploadResendsBody errorOutput should have been nil: %+v", errorOutput)
+	}
+	if len(bodies) != 2 || bodies[0] != "foobar" || bodies[1] != "foobar" {
+		t.Errorf("RetryUploadResendsBody the body was not sent twice: %q", bodies)
+	}
+}
+
+func TestRetryContextCancelledWhileWaiting(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		httpmock.NewStringResponder(503, `{"error":"Service Unavailable","status":503,"message":""}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	policy := testRetryPolicy()
+	policy.BaseDelay = time.Minute
+	policy.MaxDelay = time.Minute
+	client.SetRetryPolicy(policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, errorOutput := client.GetRootWithContext(ctx)
+
+	if !errors.Is(errorOutput.Err(), context.DeadlineExceeded) {
+		t.Errorf("RetryContextCancelledWhileWaiting error was not context.DeadlineExceeded: %v", errorOutput.Err())
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("RetryContextCancelledWhileWaiting the request was not sent once: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+		Jitter:    0.5,
+	}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, max := range expected {
+		delay := policy.delay(i+1, http.Header{}, time.Now())
+		if delay > max || delay < max/2 {
+			t.Errorf("RetryPolicyDelay attempt %d delay was not between %s and %s: %s", i+1, max/2, max, delay)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	if delay := policy.delay(1, header, time.Now()); delay != time.Second {
+		t.Errorf("RetryPolicyDelay Retry-After was not limited to MaxDelay: %s", delay)
+	}
+
+	policy.MaxDelay = 0
+	if delay := policy.delay(1, header, time.Now()); delay != 7*time.Second {
+		t.Errorf("RetryPolicyDelay Retry-After seconds was not honored: %s", delay)
+	}
+
+	header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if delay := policy.delay(1, header, time.Now()); delay < 59*time.Minute || delay > time.Hour {
+		t.Errorf("RetryPolicyDelay Retry-After date was not honored: %s", delay)
+	}
+}
+
+func TestDefaultRetryPolicyDelete(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("DELETE", "https://api.twitch.tv/kraken/channels/1234/stream_key",
+		httpmock.NewStringResponder(503, `{"error":"Service Unavailable","status":503,"message":""}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	client.SetRetryPolicy(DefaultRetryPolicy())
+
+	_, errorOutput := client.ResetStreamKey(&ResetStreamKeyInput{ChannelID: 1234})
+
+	if errorOutput == nil {
+		t.Fatal("DefaultRetryPolicyDelete errorOutput should not have been nil")
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("DefaultRetryPolicyDelete the stream key was reset more than once: %d", httpmock.GetTotalCallCount())
+	}
+}
@@ -1,6 +1,9 @@
 package twitch
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // RootOutput the output for the Root object
 type RootOutput struct {
@@ -24,7 +27,12 @@ type RootTokenAuthorization struct {
 
 // GetRoot - the base API request that is used to verify the users details
 func (c *Client) GetRoot() (*RootOutput, *ErrorOutput) {
+	return c.GetRootWithContext(context.Background())
+}
+
+// GetRootWithContext - the same as GetRoot but the request is bound to the context
+func (c *Client) GetRootWithContext(ctx context.Context) (*RootOutput, *ErrorOutput) {
 	output := new(RootOutput)
-	errorOutput := c.sendAPIRequest("GET", "", nil, output)
+	errorOutput := c.sendAPIRequest(ctx, "GetRoot", "GET", "", nil, output)
 	return output, errorOutput
 }
@@ -0,0 +1,139 @@
+package twitch
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// endpointScopes the scopes the access token needs for each endpoint, endpoints not listed need none
+var endpointScopes = map[string][]string{
+	"GetChannel":                     {"channel_read"},
+	"GetChannelEditors":              {"channel_read"},
+	"UpdateChannel":                  {"channel_editor"},
+	"UpdateChannelFields":            {"channel_editor"},
+	"GetChannelSubscribers":          {"channel_subscriptions"},
+	"CheckChannelSubscriptionByUser": {"channel_check_subscription"},
+	"StartChannelCommercial":         {"channel_commercial"},
+	"ResetStreamKey":                 {"channel_stream"},
+	"ListBlocks":                     {"user_blocks_read"},
+	"BlockUser":                      {"user_blocks_edit"},
+	"UnblockUser":                    {"user_blocks_edit"},
+	"CreateChannelFeedPost":          {"channel_feed_edit"},
+	"DeleteChannelFeedPost":          {"channel_feed_edit"},
+	"CreateChannelFeedPostReaction":  {"channel_feed_edit"},
+	"DeleteChannelFeedPostReaction":  {"channel_feed_edit"},
+	"CreateVideo":                    {"channel_editor"},
+}
+
+//MissingScopeError the access token doesn't have the scopes an endpoint needs, it matches ErrForbiddenScope
+type MissingScopeError struct {
+	Endpoint string
+	Missing  []string
+}
+
+// Error - the endpoint and the scopes that are missing
+func (e *MissingScopeError) Error() string {
+	return "twitch: " + e.Endpoint + " requires the missing scopes: " + strings.Join(e.Missing, ", ")
+}
+
+// Is - a missing scope is a forbidden scope
+func (e *MissingScopeError) Is(target error) bool {
+	return target == ErrForbiddenScope
+}
+
+// RequiredScopes - the scopes the access token needs to call the endpoint, e.g. "GetChannelSubscribers"
+func RequiredScopes(endpoint string) []string {
+	return append([]string(nil), endpointScopes[endpoint]...)
+}
+
+//scopeCache the scopes of the current access token, looked up with GetRoot when the token doesn't list them.
+//Only one token is kept so the scopes of a token that has been replaced, e.g. by a refresh, are dropped.
+type scopeCache struct {
+	mu     sync.Mutex
+	token  string
+	scopes []string
+}
+
+// SetScopeCheck - check the access token has the scopes an endpoint needs before sending the request
+func (c *Client) SetScopeCheck(enabled bool) {
+	c.scopeCache = nil
+	if enabled {
+		c.scopeCache = &scopeCache{}
+	}
+}
+
+//WithScopeCheck check the access token has the scopes an endpoint needs before sending the request
+func WithScopeCheck() ClientOption {
+	return func(c *Client) {
+		c.SetScopeCheck(true)
+	}
+}
+
+//checkScopes fail fast when the token is missing a scope required by the endpoint of the context
+func (c *Client) checkScopes(ctx context.Context, token *Token) error {
+	endpoint := EndpointFromContext(ctx)
+	required := endpointScopes[endpoint]
+	if c.scopeCache == nil || len(required) == 0 {
+		return nil
+	}
+
+	granted, ok, err := c.tokenScopes(ctx, token)
+	if err != nil || !ok {
+		// A token Twitch says isn't valid is left for the endpoint to reject, so it can be refreshed
+		return err
+	}
+	missing := []string{}
+	for _, scope := range required {
+		if !containsString(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingScopeError{Endpoint: endpoint, Missing: missing}
+	}
+	return nil
+}
+
+//tokenScopes the scopes granted to the token, ok is false when Twitch says the token isn't valid
+func (c *Client) tokenScopes(ctx context.Context, token *Token) ([]string, bool, error) {
+	if len(token.Scopes) > 0 {
+		return token.Scopes, true, nil
+	}
+
+	c.scopeCache.mu.Lock()
+	if c.scopeCache.token != token.AccessToken {
+		c.scopeCache.token = token.AccessToken
+		c.scopeCache.scopes = nil
+	}
+	scopes := c.scopeCache.scopes
+	c.scopeCache.mu.Unlock()
+	if scopes != nil {
+		return scopes, true, nil
+	}
+
+	root, errorOutput := c.GetRootWithContext(ctx)
+	if errorOutput != nil {
+		return nil, false, errorOutput.Err()
+	}
+	if !root.Token.Valid {
+		return nil, false, nil
+	}
+	scopes = append([]string{}, root.Token.Authorization.Scopes...)
+
+	c.scopeCache.mu.Lock()
+	if c.scopeCache.token == token.AccessToken {
+		c.scopeCache.scopes = scopes
+	}
+	c.scopeCache.mu.Unlock()
+	return scopes, true, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package twitch
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestRequiredScopes(t *testing.T) {
+	if !reflect.DeepEqual(RequiredScopes("GetChannelSubscribers"), []string{"channel_subscriptions"}) {
+		t.Errorf("RequiredScopes GetChannelSubscribers was not channel_subscriptions: %+v", RequiredScopes("GetChannelSubscribers"))
+	}
+	if len(RequiredScopes("GetChannelByID")) != 0 {
+		t.Errorf("RequiredScopes GetChannelByID should not need any scopes: %+v", RequiredScopes("GetChannelByID"))
+	}
+
+	if !reflect.DeepEqual(RequiredScopes("GetChannelEditors"), []string{"channel_read"}) {
+		t.Errorf("RequiredScopes GetChannelEditors was not channel_read: %+v", RequiredScopes("GetChannelEditors"))
+	}
+
+	scopes := RequiredScopes("ResetStreamKey")
+	scopes[0] = "changed"
+	if RequiredScopes("ResetStreamKey")[0] != "channel_stream" {
+		t.Errorf("RequiredScopes the registry should not be changed through the returned slice")
+	}
+}
+
+func TestScopeCheck(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		httpmock.NewStringResponder(200, `{"token":{"valid":true,"authorization":{"scopes":["channel_read"]}}}`))
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(200, `{"_id":"1234"}`))
+	httpmock.RegisterResponder("POST", "https://api.twitch.tv/kraken/channel/1234/commercial",
+		httpmock.NewStringResponder(200, `{"Length":30}`))
+
+	client := NewClient(&OAuthConfig{AccessToken: "access-token"}, &http.Client{}, WithScopeCheck())
+
+	_, errorOutput := client.StartChannelCommercial(&StartChannelCommercialInput{ChannelID: 1234, Length: 30})
+
+	missingScopeError := &MissingScopeError{}
+	if !errors.As(errorOutput.Err(), &missingScopeError) {
+		t.Fatalf("ScopeCheck error was not a *MissingScopeError: %v", errorOutput.Err())
+	}
+	if missingScopeError.Endpoint != "StartChannelCommercial" || !reflect.DeepEqual(missingScopeError.Missing, []string{"channel_commercial"}) {
+		t.Errorf("ScopeCheck missing scope error was not correct: %+v", missingScopeError)
+	}
+	if !errors.Is(errorOutput.Err(), ErrForbiddenScope) {
+		t.Errorf("ScopeCheck error was not ErrForbiddenScope: %v", errorOutput.Err())
+	}
+
+	_, errorOutput = client.GetChannel()
+	if errorOutput != nil {
+		t.Errorf("ScopeCheck GetChannel errorOutput should have been nil: %+v", errorOutput)
+	}
+
+	info := httpmock.GetCallCountInfo()
+	if info["GET https://api.twitch.tv/kraken/"] != 1 {
+		t.Errorf("ScopeCheck the token scopes should have been looked up once: %d", info["GET https://api.twitch.tv/kraken/"])
+	}
+	if info["POST https://api.twitch.tv/kraken/channel/1234/commercial"] != 0 {
+		t.Errorf("ScopeCheck the commercial should not have been started")
+	}
+}
+
+func TestScopeCheckTokenScopes(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("DELETE", "https://api.twitch.tv/kraken/channels/1234/stream_key",
+		httpmock.NewStringResponder(200, `{"_id":"1234"}`))
+
+	source := &staticTokenSource{token: &Token{AccessToken: "access-token", Scopes: []string{"channel_stream"}}}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithTokenSource(source), WithScopeCheck())
+
+	_, errorOutput := client.ResetStreamKey(&ResetStreamKeyInput{ChannelID: 1234})
+
+	if errorOutput != nil {
+		t.Errorf("ScopeCheckTokenScopes errorOutput should have been nil: %+v", errorOutput)
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("ScopeCheckTokenScopes the scopes of the token should have been used without a lookup: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+func TestScopeCheckInvalidToken(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		httpmock.NewStringResponder(200, `{"token":{"valid":false,"authorization":null}}`))
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(401, `{"error":"Unauthorized","status":401,"message":"invalid oauth token"}`))
+
+	client := NewClient(&OAuthConfig{AccessToken: "expired"}, &http.Client{}, WithScopeCheck())
+
+	_, errorOutput := client.GetChannel()
+	if !errors.Is(errorOutput.Err(), ErrUnauthorized) {
+		t.Errorf("ScopeCheckInvalidToken the endpoint should have rejected the token: %v", errorOutput.Err())
+	}
+	client.GetChannel()
+
+	info := httpmock.GetCallCountInfo()
+	if info["GET https://api.twitch.tv/kraken/"] != 2 {
+		t.Errorf("ScopeCheckInvalidToken the scopes of an invalid token should not have been cached: %d", info["GET https://api.twitch.tv/kraken/"])
+	}
+}
+
+func TestScopeCheckTokenChanged(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		httpmock.NewStringResponder(200, `{"token":{"valid":true,"authorization":{"scopes":["channel_read"]}}}`))
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewStringResponder(200, `{"_id":"1234"}`))
+
+	source := &staticTokenSource{token: &Token{AccessToken: "first"}}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithTokenSource(source), WithScopeCheck())
+
+	client.GetChannel()
+	source.token = &Token{AccessToken: "second"}
+	client.GetChannel()
+	if client.scopeCache.token != "second" {
+		t.Errorf("ScopeCheckTokenChanged the cache was not cleared for the new token: %s", client.scopeCache.token)
+	}
+	source.token = &Token{AccessToken: "first"}
+	client.GetChannel()
+
+	info := httpmock.GetCallCountInfo()
+	if info["GET https://api.twitch.tv/kraken/"] != 3 {
+		t.Errorf("ScopeCheckTokenChanged the scopes should have been looked up for each change of token: %d", info["GET https://api.twitch.tv/kraken/"])
+	}
+}
+
+func TestScopeCheckDisabled(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "https://api.twitch.tv/kraken/channel/1234/commercial",
+		httpmock.NewStringResponder(200, `{"Length":30}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	_, errorOutput := client.StartChannelCommercial(&StartChannelCommercialInput{ChannelID: 1234, Length: 30})
+
+	if errorOutput != nil {
+		t.Errorf("ScopeCheckDisabled errorOutput should have been nil: %+v", errorOutput)
+	}
+	if httpmock.GetTotalCallCount() != 1 {
+		t.Errorf("ScopeCheckDisabled only the commercial should have been requested: %d", httpmock.GetTotalCallCount())
+	}
+}
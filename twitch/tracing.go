@@ -0,0 +1,82 @@
+package twitch
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// requestIDSegments the path segments that are followed by an ID and the name of the ID
+var requestIDSegments = map[string]string{
+	"channel":       "channel_id",
+	"channels":      "channel_id",
+	"feed":          "channel_id",
+	"users":         "user_id",
+	"blocks":        "target_user_id",
+	"subscriptions": "user_id",
+	"posts":         "post_id",
+	"upload":        "video_id",
+}
+
+//Tracer starts a span around every endpoint call, the twitchotel module has one for OpenTelemetry
+type Tracer interface {
+	// Start - start the span of the endpoint call, the returned context is used to send the request.
+	// Headers set on req, e.g. to propagate the trace, are sent with every attempt.
+	// req is nil for a call made of several endpoint calls, e.g. ModifyChannel, the Pages walkers
+	// and VideoUploader, the spans of the endpoint calls are then started in the returned context.
+	Start(ctx context.Context, endpoint string, req *http.Request) (context.Context, Span)
+}
+
+//Span the span of an endpoint call started by a Tracer
+type Span interface {
+	// End - finish the span, resp is the response to the last attempt and nil when there was none or req was nil, its body has already been closed
+	End(resp *http.Response, err error)
+}
+
+// SetTracer - start a span around every endpoint call, nil disables tracing
+func (c *Client) SetTracer(tracer Tracer) {
+	c.tracer = tracer
+}
+
+//WithTracer start a span around every endpoint call
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *Client) {
+		c.SetTracer(tracer)
+	}
+}
+
+//startSpan start the span of the call sending req, or of the endpoint calls made inside it when req is nil. It is nil without a tracer.
+func (c *Client) startSpan(ctx context.Context, name string, req *http.Request) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, nil
+	}
+	return c.tracer.Start(ctx, name, req)
+}
+
+//endSpan end the span when one was started
+func endSpan(span Span, resp *http.Response, err error) {
+	if span != nil {
+		span.End(resp, err)
+	}
+}
+
+//traceCalls make the endpoint calls of call inside one span named after it
+func (c *Client) traceCalls(ctx context.Context, name string, call func(ctx context.Context) *ErrorOutput) *ErrorOutput {
+	ctx, span := c.startSpan(ctx, name, nil)
+	errorOutput := call(ctx)
+	endSpan(span, nil, errorOutput.Err())
+	return errorOutput
+}
+
+// RequestIDs - the channel, user, post and video IDs in the path of a request, e.g. {"channel_id": "1234"}
+func RequestIDs(req *http.Request) map[string]string {
+	ids := map[string]string{}
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if name, ok := requestIDSegments[segments[i]]; ok && segments[i+1] != "" {
+			ids[name] = segments[i+1]
+			i++
+		}
+	}
+	return ids
+}
@@ -0,0 +1,248 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+)
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+type testSpan struct {
+	endpoint string
+	parent   *testSpan
+	ids      map[string]string
+	status   int
+	err      error
+	ended    bool
+}
+
+type testSpanKey struct{}
+
+func (t *testTracer) Start(ctx context.Context, endpoint string, req *http.Request) (context.Context, Span) {
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	span := &testSpan{endpoint: endpoint, parent: parent}
+	if req != nil {
+		span.ids = RequestIDs(req)
+		req.Header.Set("Traceparent", "00-trace-span-01")
+	}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+//endpoints the endpoint of every span started, with the endpoint of its parent when it has one
+func (t *testTracer) endpoints() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	endpoints := []string{}
+	for _, span := range t.spans {
+		if span.parent != nil {
+			endpoints = append(endpoints, span.parent.endpoint+"/"+span.endpoint)
+		} else {
+			endpoints = append(endpoints, span.endpoint)
+		}
+	}
+	return endpoints
+}
+
+func (s *testSpan) End(resp *http.Response, err error) {
+	if resp != nil {
+		s.status = resp.StatusCode
+	}
+	s.err = err
+	s.ended = true
+}
+
+func TestTracer(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234/subscriptions/5678",
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Traceparent") != "00-trace-span-01" {
+				t.Errorf("The trace header was not sent: %s", req.Header.Get("Traceparent"))
+			}
+			if req.Context().Value(testSpanKey{}) == nil {
+				t.Error("The context of the span was not used to send the request")
+			}
+			return httpmock.NewStringResponse(404, `{"error":"Not Found","status":404,"message":"User has no subscription"}`), nil
+		})
+
+	tracer := &testTracer{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithTracer(tracer))
+
+	client.CheckChannelSubscriptionByUser(&CheckChannelSubscriptionByUserInput{ChannelID: 1234, UserID: 5678})
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("One span was not started: %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.endpoint != "CheckChannelSubscriptionByUser" {
+		t.Errorf("The span endpoint was not CheckChannelSubscriptionByUser: %s", span.endpoint)
+	}
+	if span.ids["channel_id"] != "1234" || span.ids["user_id"] != "5678" {
+		t.Errorf("The span IDs were not correct: %v", span.ids)
+	}
+	if !span.ended {
+		t.Error("The span was not ended")
+	}
+	if span.status != 404 {
+		t.Errorf("The span status was not 404: %d", span.status)
+	}
+	if !errors.Is(span.err, ErrNotFound) {
+		t.Errorf("The span error was not ErrNotFound: %v", span.err)
+	}
+}
+
+func TestTracerTransportError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channel",
+		httpmock.NewErrorResponder(errors.New("connection refused")))
+
+	tracer := &testTracer{}
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	client.SetTracer(tracer)
+
+	client.GetChannel()
+
+	span := tracer.spans[0]
+	if span.status != 0 || span.err == nil {
+		t.Errorf("The span should have ended with an error and no response: %d %v", span.status, span.err)
+	}
+}
+
+func TestTracerRefresh(t *testing.T) {
+	fake := &fakeTwitch{accessToken: "access-token-0"}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	tracer := &testTracer{}
+	client := newFakeTwitchClient(server, WithTracer(tracer))
+	client.SetTokenSource(client.NewRefreshTokenSource(&Token{AccessToken: "revoked", RefreshToken: "refresh-token"}))
+
+	client.GetChannel()
+
+	if endpoints := tracer.endpoints(); !reflect.DeepEqual(endpoints, []string{"GetChannel", "GetChannel/RefreshToken"}) {
+		t.Fatalf("The replay should have been inside the span of the call: %v", endpoints)
+	}
+	span := tracer.spans[0]
+	if !span.ended || span.status != 200 || span.err != nil {
+		t.Errorf("The span should have ended with the replayed response: %d %v", span.status, span.err)
+	}
+}
+
+func TestTracerModifyChannel(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"Old","game":"Nioh"}`))
+	httpmock.RegisterResponder("PUT", "https://api.twitch.tv/kraken/channel/1234",
+		httpmock.NewStringResponder(200, `{"_id":"1234","status":"New","game":"Nioh"}`))
+
+	tracer := &testTracer{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithTracer(tracer))
+
+	client.ModifyChannel(1234, func(channel *Channel) {
+		channel.Status = "New"
+	})
+
+	expected := []string{"ModifyChannel", "ModifyChannel/GetChannelByID", "ModifyChannel/UpdateChannelFields"}
+	if endpoints := tracer.endpoints(); !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("The calls of ModifyChannel were not traced inside its span: %v", endpoints)
+	}
+	if !tracer.spans[0].ended || tracer.spans[0].ids != nil {
+		t.Errorf("The ModifyChannel span was not ended without a request: %+v", tracer.spans[0])
+	}
+}
+
+func TestTracerPages(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/channels/1234/follows",
+		func(req *http.Request) (*http.Response, error) {
+			if req.URL.Query().Get("cursor") == "" {
+				return httpmock.NewStringResponse(200, `{"_cursor":"next","_total":2,"follows":[{"user":{"_id":1}}]}`), nil
+			}
+			return httpmock.NewStringResponse(500, `{"error":"Internal Server Error","status":500,"message":""}`), nil
+		})
+
+	tracer := &testTracer{}
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithTracer(tracer))
+
+	client.GetChannelFollowersPages(&GetChannelFollowersInput{ChannelID: 1234}, func(page *GetChannelFollowersOutput, lastPage bool) bool {
+		return true
+	})
+
+	expected := []string{"GetChannelFollowersPages", "GetChannelFollowersPages/GetChannelFollowers", "GetChannelFollowersPages/GetChannelFollowers"}
+	if endpoints := tracer.endpoints(); !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("The pages were not traced inside one span: %v", endpoints)
+	}
+	if !errors.Is(tracer.spans[0].err, ErrServer) {
+		t.Errorf("The span of the pages should have ended with the error of the failed page: %v", tracer.spans[0].err)
+	}
+}
+
+func TestTracerVideoUploader(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	tracer := &testTracer{}
+	client := newFakeUploadClient(server)
+	client.SetTracer(tracer)
+	uploader := client.NewVideoUploader()
+	uploader.PartSize = MinPartSize
+
+	_, err := uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, bytes.NewReader(randomVideo(MinPartSize+1)))
+	if err != nil {
+		t.Fatalf("Upload err should have been nil: %v", err)
+	}
+
+	expected := []string{
+		"VideoUploader.Upload",
+		"VideoUploader.Upload/CreateVideo",
+		"VideoUploader.Upload/UploadVideoPart",
+		"VideoUploader.Upload/UploadVideoPart",
+		"VideoUploader.Upload/CompleteVideo",
+	}
+	if endpoints := tracer.endpoints(); !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("The calls of the upload were not traced inside its span: %v", endpoints)
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	tests := map[string]map[string]string{
+		"https://api.twitch.tv/kraken/users/1/blocks/2":               {"user_id": "1", "target_user_id": "2"},
+		"https://api.twitch.tv/kraken/feed/1234/posts/abc/reactions":  {"channel_id": "1234", "post_id": "abc"},
+		"https://uploads.twitch.tv/upload/5678?upload_token=t&part=1": {"video_id": "5678"},
+		"https://api.twitch.tv/kraken/channel":                        {},
+	}
+	for rawURL, expected := range tests {
+		req, _ := http.NewRequest("GET", rawURL, nil)
+		ids := RequestIDs(req)
+		if len(ids) != len(expected) {
+			t.Errorf("RequestIDs the IDs of %s were not correct: %v", rawURL, ids)
+		}
+		for name, id := range expected {
+			if ids[name] != id {
+				t.Errorf("RequestIDs the %s of %s was not %s: %v", name, rawURL, id, ids)
+			}
+		}
+	}
+}
@@ -2,8 +2,11 @@ package twitch
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -19,19 +22,36 @@ type OAuthConfig struct {
 
 //Client the object everything is based on
 type Client struct {
-	apiURL        string
-	apiVersion    int
-	uploadURL     string
-	uploadVersion int
-	httpClient    *http.Client
-	oauthConfig   *OAuthConfig
+	apiURL          string
+	apiVersion      int
+	uploadURL       string
+	uploadVersion   int
+	userAgent       string
+	httpClient      *http.Client
+	oauthConfig     *OAuthConfig
+	retryPolicy     *RetryPolicy
+	rateLimiter     *RateLimiter
+	uploadBandwidth *BandwidthLimiter
+	tokenSource     TokenSource
+	scopeCache      *scopeCache
+	middlewares     []Middleware
+	logger          Logger
+	logBodies       bool
+	metrics         Metrics
+	tracer          Tracer
+	cache           Cache
+	cacheTTL        time.Duration
+	idURL           string
+	now             func() time.Time
 }
 
-// ErrorOutput - Twitch Error
+// ErrorOutput - Twitch Error, use Err() to get the typed error behind it
 type ErrorOutput struct {
 	Error   string `json:"error"`
 	Status  int64  `json:"status"`
 	Message string `json:"message"`
+
+	err error
 }
 
 //User the user secton within a block
@@ -64,6 +84,8 @@ type Channel struct {
 	Status                       string    `json:"status"`
 	BroadcasterLanguage          string    `json:"broadcaster_language"`
 	Game                         string    `json:"game"`
+	Delay                        int64     `json:"delay"`
+	ChannelFeedEnabled           bool      `json:"channel_feed_enabled"`
 	Language                     string    `json:"language"`
 	CreatedAt                    time.Time `json:"created_at,omitempty"`
 	UpdatedAt                    time.Time `json:"updated_at,omitempty"`
@@ -153,19 +175,29 @@ type Video struct {
 	Channel       Channel     `json:"channel"`
 }
 
-//NewClient a nice way of creating a new Client
-func NewClient(oauthConfig *OAuthConfig, httpClient *http.Client) *Client {
+//NewClient a nice way of creating a new Client, the defaults can be changed with options
+func NewClient(oauthConfig *OAuthConfig, httpClient *http.Client, options ...ClientOption) *Client {
 	apiURL := "https://api.twitch.tv/kraken/"
 	uploadURL := "https://uploads.twitch.tv/"
 
-	return &Client{
+	c := &Client{
 		apiURL:        apiURL,
 		apiVersion:    5,
 		uploadURL:     uploadURL,
 		uploadVersion: 4,
+		idURL:         "https://id.twitch.tv/oauth2/",
+		userAgent:     "Twitchy Gopher (https://github.com/ollieparsley/twitchy-gopher",
 		httpClient:    httpClient,
 		oauthConfig:   oauthConfig,
+		now:           time.Now,
+	}
+	for _, option := range options {
+		option(c)
 	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	return c
 }
 
 //createBaseRequest Create new http request and set it all up
@@ -181,6 +213,7 @@ func (c *Client) createBaseRequest(method string, path string, params map[string
 		}
 		buffer := bytes.NewBufferString(data.Encode())
 		req, _ = http.NewRequest(method, fullURL, buffer)
+		req = withFormBody(req)
 	} else {
 		req, _ = http.NewRequest(method, fullURL, nil)
 	}
@@ -195,7 +228,7 @@ func (c *Client) createBaseRequest(method string, path string, params map[string
 	}
 
 	// Set the user-agent
-	req.Header.Add("User-Agent", "Twitchy Gopher (https://github.com/ollieparsley/twitchy-gopher")
+	req.Header.Add("User-Agent", c.userAgent)
 
 	return c.authorizeRequest(req)
 }
@@ -210,36 +243,123 @@ func (c *Client) authorizeRequest(req *http.Request) *http.Request {
 	return req
 }
 
-func (c *Client) performRequest(req *http.Request, output interface{}) *ErrorOutput {
+func (c *Client) performRequest(ctx context.Context, req *http.Request, output interface{}) *ErrorOutput {
+	ctx, span := c.startSpan(ctx, EndpointFromContext(ctx), req)
+	resp, errorOutput := c.performCall(ctx, req, output)
+	endSpan(span, resp, errorOutput.Err())
+	return errorOutput
+}
+
+//performCall send the request and record its response metadata and metrics, returning the response to the last attempt
+func (c *Client) performCall(ctx context.Context, req *http.Request, output interface{}) (*http.Response, *ErrorOutput) {
+	start := c.now()
+	resp, errorOutput, retries := c.performAttempts(ctx, req, output)
+	setResponseMetadata(ctx, resp, retries)
+	if c.metrics != nil {
+		c.metrics.ObserveRequest(newRequestMetric(EndpointFromContext(ctx), errorOutput, retries, c.now().Sub(start)))
+	}
+	return resp, errorOutput
+}
+
+//performAttempts send the request until it succeeds or the retry policy gives up, returning the last response and the number of retries
+func (c *Client) performAttempts(ctx context.Context, req *http.Request, output interface{}) (*http.Response, *ErrorOutput, int) {
+	for attempt := 1; ; attempt++ {
+		resp, errorOutput := c.performAttempt(ctx, req, output)
+		if errorOutput == nil || !c.retryPolicy.shouldRetry(ctx, req, attempt, errorOutput) {
+			return resp, errorOutput, attempt - 1
+		}
+
+		// Wait before trying again with a fresh body
+		var header http.Header
+		if resp != nil {
+			header = resp.Header
+		}
+		if err := sleepContext(ctx, c.retryPolicy.delay(attempt, header, c.now())); err != nil {
+			return resp, c.errorToOutput(err), attempt - 1
+		}
+		if err := rewindRequest(req); err != nil {
+			return resp, c.errorToOutput(err), attempt - 1
+		}
+	}
+}
+
+//performAttempt send the request once, returning the response when there was one, its body has already been read and closed
+func (c *Client) performAttempt(ctx context.Context, req *http.Request, output interface{}) (*http.Response, *ErrorOutput) {
+	// Don't bother sending anything if the context is already done
+	if err := ctx.Err(); err != nil {
+		return nil, c.errorToOutput(err)
+	}
+
+	// Answer from the cache while the response is fresh, otherwise ask Twitch whether it has changed
+	var cached *CacheEntry
+	if c.cache != nil && c.cacheable(req) && ctx.Value(noCacheContextKey{}) == nil {
+		cached = c.cache.Get(cacheKey(req))
+		if cached != nil && c.now().Before(cached.Expiry) {
+			return cachedResponse(cached), decodeOutput(cached.Body, output)
+		}
+		req.Header.Del("If-None-Match")
+		if cached != nil && cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+	}
+
+	// Wait for the rate limit budget
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, c.errorToOutput(err)
+		}
+	}
+
 	// Make the request
-	//fmt.Printf("\nURL: %+v\n", req.URL)
-	//fmt.Printf("\nHEADERS: %+v\n", req.Header)
-	resp, err := c.httpClient.Do(req)
+	start := c.now()
+	resp, err := c.roundTrip(req.WithContext(ctx))
 	if err != nil {
-		return c.errorToOutput(err)
+		if c.logger != nil {
+			c.logRequest(ctx, req, nil, nil, c.now().Sub(start), err)
+		}
+		return nil, c.errorToOutput(err)
 	}
+	defer resp.Body.Close()
 
-	//buf := new(bytes.Buffer)
-	//buf.ReadFrom(resp.Body)
-	//bodyString := buf.String()
-	//dump, err := httputil.DumpResponse(resp, true)
-	//fmt.Printf("BODY: %+v", string(dump))
+	if c.rateLimiter != nil {
+		c.rateLimiter.update(resp.StatusCode, resp.Header)
+	}
 
 	// JSON decoding
 	code := resp.StatusCode
-	if code == 204 || resp.Header.Get("Content-Length") == "0" {
-		return nil
-	} else if 200 <= code && code <= 299 {
-		decodeErr := json.NewDecoder(resp.Body).Decode(output)
-		if decodeErr != nil {
-			return c.errorToOutput(decodeErr)
+	noContent := code == 204 || (200 <= code && code <= 299 && resp.Header.Get("Content-Length") == "0")
+	var body []byte
+	if !noContent {
+		body, err = ioutil.ReadAll(resp.Body)
+	}
+	if c.logger != nil {
+		c.logRequest(ctx, req, resp, body, c.now().Sub(start), err)
+	}
+	if err != nil {
+		return resp, c.errorToOutput(err)
+	}
+	if code == 304 && cached != nil {
+		cached.Expiry = c.now().Add(c.cacheTTL)
+		c.cache.Set(cacheKey(req), cached)
+		return resp, decodeOutput(cached.Body, output)
+	}
+	if c.cache != nil && 200 <= code && code <= 299 {
+		if c.cacheable(req) && !noContent {
+			c.storeResponse(req, resp.Header, body)
+		} else if req.Method != "GET" {
+			c.cache.Invalidate(cacheResource(req))
 		}
-		return nil
+	}
+	if noContent {
+		return resp, nil
+	}
+	if 200 <= code && code <= 299 {
+		return resp, decodeOutput(body, output)
 	}
 
 	errorOutput := &ErrorOutput{}
-	json.NewDecoder(resp.Body).Decode(errorOutput)
-	return errorOutput
+	json.Unmarshal(body, errorOutput)
+	return resp, newAPIErrorOutput(code, errorOutput)
 }
 
 func (c *Client) createAPIRequest(method string, path string, params map[string]string) *http.Request {
@@ -248,7 +368,9 @@ func (c *Client) createAPIRequest(method string, path string, params map[string]
 	return req
 }
 
-func (c *Client) sendAPIRequest(method string, path string, params map[string]string, output interface{}) *ErrorOutput {
+func (c *Client) sendAPIRequest(ctx context.Context, endpoint string, method string, path string, params map[string]string, output interface{}) *ErrorOutput {
+	ctx = withEndpoint(ctx, endpoint)
+
 	// Create API request
 	req := c.createAPIRequest(method, path, params)
 
@@ -256,7 +378,7 @@ func (c *Client) sendAPIRequest(method string, path string, params map[string]st
 	req.Header.Set("Accept", fmt.Sprintf("application/vnd.twitchtv.v%d+json", c.apiVersion))
 
 	// Perform the request
-	return c.performRequest(req, output)
+	return c.performAuthorizedRequest(ctx, req, output)
 }
 
 func (c *Client) createUploadRequest(method string, path string, contentType string, body *bytes.Buffer) *http.Request {
@@ -269,6 +391,9 @@ func (c *Client) createUploadRequest(method string, path string, contentType str
 	}
 	req.Header.Add("Content-Length", strconv.Itoa(body.Len()))
 
+	// Set the user-agent
+	req.Header.Add("User-Agent", c.userAgent)
+
 	// Specify the API version
 	req.Header.Set("Accept", fmt.Sprintf("application/vnd.twitchtv.v%d+json", c.uploadVersion))
 
@@ -277,18 +402,68 @@ func (c *Client) createUploadRequest(method string, path string, contentType str
 	return req
 }
 
-func (c *Client) sendUploadRequest(method string, path string, contentType string, body *bytes.Buffer, output interface{}) *ErrorOutput {
+func (c *Client) sendUploadRequest(ctx context.Context, endpoint string, method string, path string, contentType string, body *bytes.Buffer, output interface{}) *ErrorOutput {
+	ctx = withEndpoint(ctx, endpoint)
+	if c.uploadBandwidth != nil {
+		ctx = withBodyWrapper(ctx, c.uploadBandwidth.wrap(ctx))
+	}
+
 	// Create upload request
 	req := c.createUploadRequest(method, path, contentType, body)
 
 	// Perform the request
-	return c.performRequest(req, output)
+	return c.performAuthorizedRequest(ctx, req, output)
 }
 
 func (c *Client) errorToOutput(err error) *ErrorOutput {
+	// Keep errors that have already been classified, e.g. from a token source calling the API
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return &ErrorOutput{
+			Message: apiError.Message,
+			Error:   apiError.ErrorText,
+			Status:  int64(apiError.StatusCode),
+			err:     err,
+		}
+	}
+	var transportError *TransportError
+	var decodeError *DecodeError
+	var missingScopeError *MissingScopeError
+	if errors.As(err, &transportError) || errors.As(err, &decodeError) || errors.As(err, &missingScopeError) {
+		return &ErrorOutput{
+			Message: err.Error(),
+			Error:   "Twitchy error",
+			Status:  -1,
+			err:     err,
+		}
+	}
+
 	return &ErrorOutput{
 		Message: err.Error(),
 		Error:   "Twitchy error",
 		Status:  -1,
+		err:     &TransportError{Err: err},
+	}
+}
+
+//endpointContextKey the context key of the name of the endpoint a request is for
+type endpointContextKey struct{}
+
+//withEndpoint add the name of the endpoint, e.g. "GetChannelFollowers", to the context of a request
+func withEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointContextKey{}, endpoint)
+}
+
+// EndpointFromContext - the name of the endpoint a request is for, e.g. "GetChannelFollowers", middleware can use it with req.Context()
+func EndpointFromContext(ctx context.Context) string {
+	endpoint, _ := ctx.Value(endpointContextKey{}).(string)
+	return endpoint
+}
+
+//decodeOutput decode the JSON body of a successful response into output
+func decodeOutput(body []byte, output interface{}) *ErrorOutput {
+	if err := json.Unmarshal(body, output); err != nil {
+		return newDecodeErrorOutput(err, body)
 	}
+	return nil
 }
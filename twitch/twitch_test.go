@@ -2,10 +2,15 @@ package twitch
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 )
@@ -163,7 +168,7 @@ func TestCreateUploadRequest(t *testing.T) {
 func TestPerformRequestClientError(t *testing.T) {
 	req := &http.Request{}
 	client := NewClient(&OAuthConfig{}, &http.Client{})
-	errorOutput := client.performRequest(req, new(RootOutput))
+	errorOutput := client.performRequest(context.Background(), req, new(RootOutput))
 
 	if errorOutput == nil {
 		t.Errorf("performRequestClientError errorOutput was nil")
@@ -203,3 +208,108 @@ func TestPerformRequestJSONError(t *testing.T) {
 		t.Errorf("TestPerformRequestJSONError error message was \"invalid character '{' looking for beginning of object key string\": %s", errorOutput.Message)
 	}
 }
+
+func TestPerformRequestContextCancelled(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(&OAuthConfig{}, server.Client(), WithAPIURL(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-received
+		cancel()
+	}()
+
+	done := make(chan *ErrorOutput)
+	go func() {
+		_, errorOutput := client.GetChannelByIDWithContext(ctx, &GetChannelByIDInput{ChannelID: 1234})
+		done <- errorOutput
+	}()
+
+	select {
+	case errorOutput := <-done:
+		if errorOutput == nil {
+			t.Fatalf("TestPerformRequestContextCancelled errorOutput was nil")
+		}
+		if strings.Contains(errorOutput.Message, context.Canceled.Error()) == false {
+			t.Errorf("TestPerformRequestContextCancelled error message didn't contain %q: %s", context.Canceled.Error(), errorOutput.Message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("TestPerformRequestContextCancelled request was not aborted by the cancelled context")
+	}
+}
+
+func TestPerformRequestContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(&OAuthConfig{}, server.Client(), WithUploadURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, errorOutput := client.UploadVideoPartWithContext(ctx, &UploadVideoPartInput{
+		VideoID: "v1234",
+		Token:   "upload-token",
+		Part:    1,
+		Body:    bytes.NewBufferString("foobar"),
+	})
+
+	if errorOutput == nil {
+		t.Fatalf("TestPerformRequestContextDeadline errorOutput was nil")
+	}
+	if strings.Contains(errorOutput.Message, context.DeadlineExceeded.Error()) == false {
+		t.Errorf("TestPerformRequestContextDeadline error message didn't contain %q: %s", context.DeadlineExceeded.Error(), errorOutput.Message)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("TestPerformRequestContextDeadline request was not aborted at the deadline")
+	}
+}
+
+func TestPerformRequestContextAlreadyCancelled(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://api.twitch.tv/kraken/",
+		httpmock.NewStringResponder(200, `{"token":{"valid":true}}`))
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, errorOutput := client.GetRootWithContext(ctx)
+
+	if errorOutput == nil {
+		t.Fatalf("TestPerformRequestContextAlreadyCancelled errorOutput was nil")
+	}
+	if httpmock.GetTotalCallCount() != 0 {
+		t.Errorf("TestPerformRequestContextAlreadyCancelled request should not have been sent: %d", httpmock.GetTotalCallCount())
+	}
+}
+
+//requestForm the form encoded body of an API request, it is sent without a Content-Type so ParseForm ignores it
+func requestForm(req *http.Request) url.Values {
+	body, _ := ioutil.ReadAll(req.Body)
+	form, _ := url.ParseQuery(string(body))
+	return form
+}
@@ -0,0 +1,151 @@
+package twitch
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+//UploadProgress how far an upload has got, reported as the bytes of each part are sent
+type UploadProgress struct {
+	Part           int           // The part that was sent
+	PartBytesSent  int64         // Bytes of the part sent so far, a retried part starts again from 0
+	PartBytes      int64         // Bytes in the part
+	BytesSent      int64         // Bytes of the upload sent so far, including parts completed before it was resumed
+	TotalBytes     int64         // Bytes in the upload, 0 when unknown
+	PartsCompleted int           // Parts uploaded so far
+	TotalParts     int           // Parts in the upload, 0 when unknown
+	Throughput     float64       // Bytes sent per second since the upload started
+	ETA            time.Duration // Time left at the current throughput, 0 when unknown
+}
+
+//ProgressFunc receives the progress of an upload. It is never called for two parts at the same time and should return quickly.
+type ProgressFunc func(progress *UploadProgress)
+
+//progressTracker adds up the bytes sent by the parts of an upload and reports them
+type progressTracker struct {
+	mu  sync.Mutex
+	fn  ProgressFunc
+	now func() time.Time
+
+	start          time.Time
+	totalBytes     int64
+	totalParts     int
+	resumedBytes   int64 // bytes completed before the upload started, they don't count towards the throughput
+	completedBytes int64
+	completedParts int
+	inFlight       map[int]int64 // bytes sent of each part being uploaded
+}
+
+//newProgressTracker a tracker reporting to fn, nil when fn is nil
+func newProgressTracker(fn ProgressFunc, now func() time.Time, totalBytes int64, totalParts int) *progressTracker {
+	if fn == nil {
+		return nil
+	}
+	return &progressTracker{
+		fn:         fn,
+		now:        now,
+		start:      now(),
+		totalBytes: totalBytes,
+		totalParts: totalParts,
+		inFlight:   map[int]int64{},
+	}
+}
+
+//resume count parts that were uploaded before the upload started
+func (t *progressTracker) resume(bytes int64, parts int) {
+	t.resumedBytes += bytes
+	t.completedBytes += bytes
+	t.completedParts += parts
+}
+
+//wrap a function wrapping the body of the part so the bytes read from it are reported
+func (t *progressTracker) wrap(part int, partBytes int64) func(io.ReadCloser) io.ReadCloser {
+	return func(body io.ReadCloser) io.ReadCloser {
+		return &progressReader{ReadCloser: body, report: func(sent int64) {
+			t.sent(part, partBytes, sent)
+		}}
+	}
+}
+
+//sent report the bytes of the part sent so far
+func (t *progressTracker) sent(part int, partBytes int64, sent int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inFlight[part] = sent
+	t.report(part, partBytes, sent)
+}
+
+//completed report that the part has been uploaded
+func (t *progressTracker) completed(part int, partBytes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.inFlight, part)
+	t.completedBytes += partBytes
+	t.completedParts++
+	t.report(part, partBytes, partBytes)
+}
+
+//report call the function with the progress, the lock must be held
+func (t *progressTracker) report(part int, partBytes int64, partSent int64) {
+	progress := &UploadProgress{
+		Part:           part,
+		PartBytesSent:  partSent,
+		PartBytes:      partBytes,
+		BytesSent:      t.completedBytes,
+		TotalBytes:     t.totalBytes,
+		PartsCompleted: t.completedParts,
+		TotalParts:     t.totalParts,
+	}
+	for _, sent := range t.inFlight {
+		progress.BytesSent += sent
+	}
+
+	if elapsed := t.now().Sub(t.start).Seconds(); elapsed > 0 {
+		progress.Throughput = float64(progress.BytesSent-t.resumedBytes) / elapsed
+	}
+	if progress.Throughput > 0 && t.totalBytes > progress.BytesSent {
+		progress.ETA = time.Duration(float64(t.totalBytes-progress.BytesSent) / progress.Throughput * float64(time.Second))
+	}
+	t.fn(progress)
+}
+
+//progressReader counts the bytes read from a request body
+type progressReader struct {
+	io.ReadCloser
+	sent   int64
+	report func(sent int64)
+}
+
+// Read - read from the body and report the bytes read so far
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.report(r.sent)
+	}
+	return n, err
+}
+
+//bodyWrapperContextKey the context key of the function wrapping the body of an upload request
+type bodyWrapperContextKey struct{}
+
+//withBodyWrapper wrap the body of the request each time it is sent, after any wrapper already in the context
+func withBodyWrapper(ctx context.Context, wrap func(io.ReadCloser) io.ReadCloser) context.Context {
+	if previous := bodyWrapperFromContext(ctx); previous != nil {
+		inner := wrap
+		wrap = func(body io.ReadCloser) io.ReadCloser {
+			return inner(previous(body))
+		}
+	}
+	return context.WithValue(ctx, bodyWrapperContextKey{}, wrap)
+}
+
+//bodyWrapperFromContext the function wrapping the body of the request, nil when there isn't one
+func bodyWrapperFromContext(ctx context.Context) func(io.ReadCloser) io.ReadCloser {
+	wrap, _ := ctx.Value(bodyWrapperContextKey{}).(func(io.ReadCloser) io.ReadCloser)
+	return wrap
+}
@@ -0,0 +1,128 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadVideoPartProgress(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	client := newFakeUploadClient(server)
+	progress := []UploadProgress{}
+	_, errorOutput := client.UploadVideoPart(&UploadVideoPartInput{
+		VideoID: "v123456",
+		Token:   "upload-token",
+		Part:    1,
+		Body:    bytes.NewBuffer(randomVideo(256 * 1024)),
+		Progress: func(p *UploadProgress) {
+			progress = append(progress, *p)
+		},
+	})
+
+	if errorOutput != nil {
+		t.Fatalf("UploadVideoPart errorOutput should have been nil: %+v", errorOutput)
+	}
+	if len(progress) < 2 {
+		t.Fatalf("UploadVideoPart the progress was not reported while sending: %d", len(progress))
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i].PartBytesSent < progress[i-1].PartBytesSent {
+			t.Errorf("UploadVideoPart the bytes sent went down: %d %d", progress[i-1].PartBytesSent, progress[i].PartBytesSent)
+		}
+	}
+	last := progress[len(progress)-1]
+	if last.Part != 1 || last.PartBytesSent != 256*1024 || last.PartBytes != 256*1024 || last.BytesSent != last.TotalBytes {
+		t.Errorf("UploadVideoPart the whole part was not reported: %+v", last)
+	}
+	if last.PartsCompleted != 1 || last.TotalParts != 1 || last.ETA != 0 {
+		t.Errorf("UploadVideoPart the part was not reported as completed: %+v", last)
+	}
+}
+
+func TestVideoUploaderProgress(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	video := randomVideo(2*MinPartSize + 10)
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+	uploader.Workers = 2
+	progress := []UploadProgress{}
+	uploader.Progress = func(p *UploadProgress) {
+		progress = append(progress, *p)
+	}
+
+	_, err := uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, bytes.NewReader(video))
+
+	if err != nil {
+		t.Fatalf("Upload err should have been nil: %s", err)
+	}
+	if len(progress) == 0 {
+		t.Fatal("Upload the progress was not reported")
+	}
+	eta := false
+	for i, p := range progress {
+		if i > 0 && p.BytesSent < progress[i-1].BytesSent {
+			t.Errorf("Upload the bytes sent went down: %d %d", progress[i-1].BytesSent, p.BytesSent)
+		}
+		eta = eta || p.ETA > 0
+	}
+	if !eta {
+		t.Error("Upload an ETA was not reported")
+	}
+	last := progress[len(progress)-1]
+	if last.BytesSent != int64(len(video)) || last.TotalBytes != int64(len(video)) {
+		t.Errorf("Upload the whole video was not reported: %+v", last)
+	}
+	if last.PartsCompleted != 3 || last.TotalParts != 3 || last.Throughput <= 0 || last.ETA != 0 {
+		t.Errorf("Upload the video was not reported as completed: %+v", last)
+	}
+}
+
+func TestUploadSessionProgress(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "twitch-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "video.mp4")
+	ioutil.WriteFile(path, randomVideo(2*MinPartSize+10), 0600)
+
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+	session, err := uploader.StartSession(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, path)
+	if err != nil {
+		t.Fatalf("StartSession err should have been nil: %s", err)
+	}
+	session.CompletedParts = []int{1}
+
+	progress := []UploadProgress{}
+	uploader.Progress = func(p *UploadProgress) {
+		progress = append(progress, *p)
+	}
+	_, err = uploader.Resume(context.Background(), session, nil)
+
+	if err != nil {
+		t.Fatalf("Resume err should have been nil: %s", err)
+	}
+	if progress[0].BytesSent < MinPartSize || progress[0].PartsCompleted != 1 || progress[0].Part != 2 {
+		t.Errorf("Resume the completed part was not counted: %+v", progress[0])
+	}
+	last := progress[len(progress)-1]
+	if last.PartsCompleted != 3 || last.BytesSent != 2*MinPartSize+10 {
+		t.Errorf("Resume the video was not reported as completed: %+v", last)
+	}
+}
@@ -0,0 +1,300 @@
+package twitch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// fingerprintSample the bytes read from the start and end of a file for its fingerprint
+const fingerprintSample = 1024 * 1024
+
+// ErrFileChanged the file being resumed isn't the file the upload was started with
+var ErrFileChanged = errors.New("twitch: the video file has changed since the upload was started")
+
+// ErrSessionMismatch the saved session is for another file than the one being uploaded
+var ErrSessionMismatch = errors.New("twitch: the upload session is for another file")
+
+// ErrInvalidSession the saved session is corrupt, e.g. it was cut short or edited, and can't be resumed
+var ErrInvalidSession = errors.New("twitch: the upload session is invalid")
+
+//FileFingerprint identifies the content of a file without reading all of it
+type FileFingerprint struct {
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Checksum string    `json:"checksum"` // SHA-256 of the first and last MiB
+}
+
+// Equal - whether both fingerprints are of the same content
+func (f FileFingerprint) Equal(other FileFingerprint) bool {
+	return f.Size == other.Size && f.ModTime.Equal(other.ModTime) && f.Checksum == other.Checksum
+}
+
+//UploadSession the state of a video file upload. Save it after each part so the upload can be resumed
+//by another process, e.g. after a crash, without uploading the completed parts again.
+type UploadSession struct {
+	Path           string          `json:"path"`
+	Video          Video           `json:"video"`
+	Token          string          `json:"token"`
+	PartSize       int             `json:"part_size"`
+	Fingerprint    FileFingerprint `json:"fingerprint"`
+	CompletedParts []int           `json:"completed_parts"`
+}
+
+// LoadUploadSession - read a session saved with Save, ErrInvalidSession is returned when it can't be resumed
+func LoadUploadSession(path string) (*UploadSession, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	session := &UploadSession{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSession, err)
+	}
+	if err := session.validate(); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+//validate check the session describes an upload that can be resumed, the parts are worked out from it
+func (s *UploadSession) validate() error {
+	if s.Video.ID == "" {
+		return fmt.Errorf("%w: no video", ErrInvalidSession)
+	}
+	if s.PartSize <= 0 {
+		return fmt.Errorf("%w: part size %d", ErrInvalidSession, s.PartSize)
+	}
+	if s.Fingerprint.Size <= 0 {
+		return fmt.Errorf("%w: file size %d", ErrInvalidSession, s.Fingerprint.Size)
+	}
+	for _, part := range s.CompletedParts {
+		if part < 1 || part > s.Parts() {
+			return fmt.Errorf("%w: part %d of %d", ErrInvalidSession, part, s.Parts())
+		}
+	}
+	sort.Ints(s.CompletedParts)
+	return nil
+}
+
+// Save - write the session to path, the file is replaced in one go so a crash never leaves half a session
+func (s *UploadSession) Save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// Parts - the number of parts the file is uploaded in
+func (s *UploadSession) Parts() int {
+	return int((s.Fingerprint.Size + int64(s.PartSize) - 1) / int64(s.PartSize))
+}
+
+// PartCompleted - whether the part has been uploaded
+func (s *UploadSession) PartCompleted(part int) bool {
+	i := sort.SearchInts(s.CompletedParts, part)
+	return i < len(s.CompletedParts) && s.CompletedParts[i] == part
+}
+
+//partBytes the number of bytes in the part, only the last part can be smaller than PartSize
+func (s *UploadSession) partBytes(part int) int64 {
+	offset := int64(part-1) * int64(s.PartSize)
+	if offset+int64(s.PartSize) > s.Fingerprint.Size {
+		return s.Fingerprint.Size - offset
+	}
+	return int64(s.PartSize)
+}
+
+//completePart record the part as uploaded, keeping the parts in order
+func (s *UploadSession) completePart(part int) {
+	if s.PartCompleted(part) {
+		return
+	}
+	s.CompletedParts = append(s.CompletedParts, part)
+	sort.Ints(s.CompletedParts)
+}
+
+// StartSession - create the video for the file at path, nothing is uploaded until the session is resumed
+func (u *VideoUploader) StartSession(ctx context.Context, video *CreateVideoInput, path string) (*UploadSession, error) {
+	partSize, err := u.partSize()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.validate(path); err != nil {
+		return nil, err
+	}
+	fingerprint, err := fingerprintFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint.Size == 0 {
+		return nil, ErrEmptyVideo
+	}
+
+	created, errorOutput := u.client.CreateVideoWithContext(ctx, video)
+	if errorOutput != nil {
+		return nil, &UploadError{Err: errorOutput.Err()}
+	}
+	return &UploadSession{
+		Path:           path,
+		Video:          created.Video,
+		Token:          created.Upload.Token,
+		PartSize:       partSize,
+		Fingerprint:    fingerprint,
+		CompletedParts: []int{},
+	}, nil
+}
+
+// Resume - upload the parts of the session that haven't been uploaded and complete the video.
+// ErrFileChanged is returned when the file is no longer the one the session was started with.
+// checkpoint, when not nil, is called after each part is uploaded, an error from it stops the upload.
+// Parts can finish out of order when there is more than one worker, CompletedParts is kept sorted.
+func (u *VideoUploader) Resume(ctx context.Context, session *UploadSession, checkpoint func(*UploadSession) error) (*Video, error) {
+	return u.traceUpload(ctx, "VideoUploader.Resume", func(ctx context.Context) (*Video, error) {
+		return u.resume(ctx, session, checkpoint)
+	})
+}
+
+//resume upload the remaining parts of the session and complete the video
+func (u *VideoUploader) resume(ctx context.Context, session *UploadSession, checkpoint func(*UploadSession) error) (*Video, error) {
+	file, err := os.Open(session.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fingerprint, err := fingerprintFile(session.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !fingerprint.Equal(session.Fingerprint) {
+		return nil, ErrFileChanged
+	}
+
+	videoID := session.Video.ID
+	// The session changes as parts complete, so the parts to upload are picked first
+	pending := []int{}
+	for part := 1; part <= session.Parts(); part++ {
+		if !session.PartCompleted(part) {
+			pending = append(pending, part)
+		}
+	}
+	next := func(buffer []byte) (int, []byte, error) {
+		if len(pending) == 0 {
+			return 0, nil, nil
+		}
+		part := pending[0]
+		pending = pending[1:]
+
+		offset := int64(part-1) * int64(session.PartSize)
+		size := session.partBytes(part)
+		if _, err := file.ReadAt(buffer[:size], offset); err != nil && err != io.EOF {
+			return 0, nil, &UploadError{VideoID: videoID, Part: part, Err: err}
+		}
+		return part, buffer[:size], nil
+	}
+	progress := newProgressTracker(u.Progress, u.client.now, session.Fingerprint.Size, session.Parts())
+	if progress != nil {
+		for _, part := range session.CompletedParts {
+			progress.resume(session.partBytes(part), 1)
+		}
+	}
+	err = u.uploadParts(ctx, videoID, session.Token, session.PartSize, next, progress, func(part int) error {
+		session.completePart(part)
+		if checkpoint != nil {
+			return checkpoint(session)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, errorOutput := u.client.CompleteVideoWithContext(ctx, &CompleteVideoInput{VideoID: videoID, Token: session.Token})
+	if errorOutput != nil {
+		return nil, &UploadError{VideoID: videoID, Err: errorOutput.Err()}
+	}
+	return &session.Video, nil
+}
+
+// UploadFileResumable - upload the video file at path, saving the session to sessionPath after each part.
+// When sessionPath already holds a session the upload carries on from it instead of creating another video,
+// ErrSessionMismatch is returned when it was started for another path.
+// The session file is removed once the video is complete.
+func (u *VideoUploader) UploadFileResumable(ctx context.Context, video *CreateVideoInput, path string, sessionPath string) (*Video, error) {
+	return u.traceUpload(ctx, "VideoUploader.UploadFileResumable", func(ctx context.Context) (*Video, error) {
+		return u.uploadFileResumable(ctx, video, path, sessionPath)
+	})
+}
+
+//uploadFileResumable start or load the session for the file and resume it
+func (u *VideoUploader) uploadFileResumable(ctx context.Context, video *CreateVideoInput, path string, sessionPath string) (*Video, error) {
+	session, err := LoadUploadSession(sessionPath)
+	if os.IsNotExist(err) {
+		session, err = u.StartSession(ctx, video, path)
+		if err == nil {
+			err = session.Save(sessionPath)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The video details can differ between runs, e.g. a ViewableAt worked out from the time, so only the
+	// file is compared. Resuming checks its content is still the same.
+	if session.Path != path {
+		return nil, ErrSessionMismatch
+	}
+
+	output, err := u.resume(ctx, session, func(session *UploadSession) error {
+		return session.Save(sessionPath)
+	})
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(sessionPath)
+	return output, nil
+}
+
+//fingerprintFile the fingerprint of the file at path
+func fingerprintFile(path string) (FileFingerprint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FileFingerprint{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return FileFingerprint{}, err
+	}
+
+	hash := sha256.New()
+	size := info.Size()
+	if _, err := io.Copy(hash, io.NewSectionReader(file, 0, fingerprintSample)); err != nil {
+		return FileFingerprint{}, err
+	}
+	if size > fingerprintSample {
+		tail := size - fingerprintSample
+		if tail < fingerprintSample {
+			tail = fingerprintSample
+		}
+		if _, err := io.Copy(hash, io.NewSectionReader(file, tail, fingerprintSample)); err != nil {
+			return FileFingerprint{}, err
+		}
+	}
+
+	return FileFingerprint{
+		Size:     size,
+		ModTime:  info.ModTime().UTC(),
+		Checksum: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,228 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestUploadFileResumable(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "twitch-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	video := randomVideo(2*MinPartSize + 10)
+	path := filepath.Join(dir, "video.mp4")
+	sessionPath := filepath.Join(dir, "video.session")
+	ioutil.WriteFile(path, video, 0600)
+
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+
+	// The second part fails, the first part is kept in the session
+	uploads.failures[2] = 1
+	_, err = uploader.UploadFileResumable(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, path, sessionPath)
+	if err == nil {
+		t.Fatal("UploadFileResumable the failed part was not returned")
+	}
+	session, err := LoadUploadSession(sessionPath)
+	if err != nil {
+		t.Fatalf("LoadUploadSession err should have been nil: %s", err)
+	}
+	if !reflect.DeepEqual(session.CompletedParts, []int{1}) || session.Token != "upload-token" || session.Video.ID != "v123456" {
+		t.Errorf("UploadFileResumable the session was not saved: %+v", session)
+	}
+
+	output, err := uploader.UploadFileResumable(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, path, sessionPath)
+	if err != nil {
+		t.Fatalf("UploadFileResumable err should have been nil: %s", err)
+	}
+	if output.ID != "v123456" {
+		t.Errorf("UploadFileResumable the video was not returned: %+v", output)
+	}
+	if uploads.created != 1 {
+		t.Errorf("UploadFileResumable the video was created again: %d", uploads.created)
+	}
+	if len(uploads.tokens) != 4 {
+		t.Errorf("UploadFileResumable the completed part was uploaded again: %d", len(uploads.tokens))
+	}
+	if !bytes.Equal(uploads.content(), video) || !uploads.completed {
+		t.Error("UploadFileResumable the video was not uploaded")
+	}
+	if _, err := os.Stat(sessionPath); !os.IsNotExist(err) {
+		t.Errorf("UploadFileResumable the session was not removed: %v", err)
+	}
+}
+
+func TestUploadSessionFileChanged(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "twitch-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "video.mp4")
+	ioutil.WriteFile(path, randomVideo(MinPartSize+10), 0600)
+
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+	session, err := uploader.StartSession(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, path)
+	if err != nil {
+		t.Fatalf("StartSession err should have been nil: %s", err)
+	}
+	if session.Parts() != 2 {
+		t.Errorf("StartSession the file was not 2 parts: %d", session.Parts())
+	}
+
+	// Same size and a different last byte
+	changed := randomVideo(MinPartSize + 10)
+	changed[len(changed)-1]++
+	ioutil.WriteFile(path, changed, 0600)
+
+	_, err = uploader.Resume(context.Background(), session, nil)
+	if err != ErrFileChanged {
+		t.Errorf("Resume the changed file error was not returned: %v", err)
+	}
+	if len(uploads.tokens) != 0 {
+		t.Errorf("Resume the changed file should not have been uploaded: %d", len(uploads.tokens))
+	}
+}
+
+func TestUploadFileResumableMismatch(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "twitch-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "video.mp4")
+	otherPath := filepath.Join(dir, "other.mp4")
+	sessionPath := filepath.Join(dir, "video.session")
+	ioutil.WriteFile(path, randomVideo(MinPartSize+10), 0600)
+	ioutil.WriteFile(otherPath, randomVideo(MinPartSize+10), 0600)
+
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+	viewableAt := time.Now().Add(24 * time.Hour)
+	video := &CreateVideoInput{ChannelID: 1234, Title: "Test", Viewable: "private", ViewableAt: &viewableAt}
+
+	// The first part fails so the session is kept
+	uploads.failures[1] = 1
+	if _, err := uploader.UploadFileResumable(context.Background(), video, path, sessionPath); err == nil {
+		t.Fatal("UploadFileResumable the failed part was not returned")
+	}
+
+	_, err = uploader.UploadFileResumable(context.Background(), video, otherPath, sessionPath)
+	if err != ErrSessionMismatch {
+		t.Errorf("UploadFileResumable the session for another file was used: %v", err)
+	}
+	if len(uploads.tokens) != 1 || uploads.completed {
+		t.Errorf("UploadFileResumable nothing should have been uploaded with the wrong session: %d", len(uploads.tokens))
+	}
+
+	// Restarted later, so the publish time worked out from the clock is different
+	viewableAt = time.Now().Add(25 * time.Hour)
+	_, err = uploader.UploadFileResumable(context.Background(), video, path, sessionPath)
+	if err != nil {
+		t.Errorf("UploadFileResumable the session for the same file was not resumed: %v", err)
+	}
+	if uploads.created != 1 || !uploads.completed {
+		t.Errorf("UploadFileResumable the video was not completed from the session: %d", uploads.created)
+	}
+}
+
+func TestLoadUploadSessionInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "twitch-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sessionPath := filepath.Join(dir, "video.session")
+
+	tests := map[string]string{
+		"empty":             `{}`,
+		"zero part size":    `{"video":{"_id":"v1234"},"part_size":0,"fingerprint":{"size":100}}`,
+		"negative size":     `{"video":{"_id":"v1234"},"part_size":10,"fingerprint":{"size":-1}}`,
+		"part out of range": `{"video":{"_id":"v1234"},"part_size":10,"fingerprint":{"size":100},"completed_parts":[1,11]}`,
+		"part zero":         `{"video":{"_id":"v1234"},"part_size":10,"fingerprint":{"size":100},"completed_parts":[0]}`,
+		"cut short":         `{"video":{"_id":"v1234"},"part_si`,
+	}
+	for name, content := range tests {
+		ioutil.WriteFile(sessionPath, []byte(content), 0600)
+		if _, err := LoadUploadSession(sessionPath); !errors.Is(err, ErrInvalidSession) {
+			t.Errorf("LoadUploadSession %s the session should have been invalid: %v", name, err)
+		}
+	}
+
+	// A corrupt session is an error for the upload, not a panic
+	path := filepath.Join(dir, "video.mp4")
+	ioutil.WriteFile(path, randomVideo(100), 0600)
+	ioutil.WriteFile(sessionPath, []byte(`{"path":"`+path+`","video":{"_id":"v1234"},"part_size":0}`), 0600)
+	uploader := NewClient(&OAuthConfig{}, nil).NewVideoUploader()
+	if _, err := uploader.UploadFileResumable(context.Background(), &CreateVideoInput{ChannelID: 1234}, path, sessionPath); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("UploadFileResumable the corrupt session error was not returned: %v", err)
+	}
+}
+
+func TestUploadSessionWorkers(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "twitch-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	video := randomVideo(4*MinPartSize + 10)
+	path := filepath.Join(dir, "video.mp4")
+	ioutil.WriteFile(path, video, 0600)
+
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+	uploader.Workers = 3
+	session, err := uploader.StartSession(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, path)
+	if err != nil {
+		t.Fatalf("StartSession err should have been nil: %s", err)
+	}
+	session.CompletedParts = []int{2}
+	uploads.parts[2] = video[MinPartSize : 2*MinPartSize]
+
+	checkpoints := 0
+	_, err = uploader.Resume(context.Background(), session, func(session *UploadSession) error {
+		checkpoints++
+		if !sort.IntsAreSorted(session.CompletedParts) {
+			t.Errorf("Resume the completed parts were not in order: %v", session.CompletedParts)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Resume err should have been nil: %s", err)
+	}
+	if checkpoints != 4 || !reflect.DeepEqual(session.CompletedParts, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("Resume the parts were not all completed: %d %v", checkpoints, session.CompletedParts)
+	}
+	if !bytes.Equal(uploads.content(), video) || !uploads.completed {
+		t.Error("Resume the video was not uploaded")
+	}
+}
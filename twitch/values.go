@@ -0,0 +1,16 @@
+package twitch
+
+// String - a pointer to the string, for optional input fields
+func String(v string) *string {
+	return &v
+}
+
+// Int64 - a pointer to the int64, for optional input fields
+func Int64(v int64) *int64 {
+	return &v
+}
+
+// Bool - a pointer to the bool, for optional input fields
+func Bool(v bool) *bool {
+	return &v
+}
@@ -2,8 +2,12 @@ package twitch
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 //Upload details of an upload
@@ -12,10 +16,21 @@ type Upload struct {
 	Token string `json:"token"`
 }
 
-//CreateVideoInput the parameters used to create a video - V4
+// ErrMissingChannelID API v5 creates videos on a channel ID, ChannelName is only used by API v4
+var ErrMissingChannelID = errors.New("twitch: CreateVideo needs a ChannelID with API v5")
+
+//CreateVideoInput the parameters used to create a video, empty fields are left to Twitch's defaults.
+//API v5 creates the video on ChannelID, ChannelName is only sent by API v4.
 type CreateVideoInput struct {
+	ChannelID   int64
 	ChannelName string
 	Title       string
+	Description string
+	Game        string
+	Language    string     // e.g. "en"
+	TagList     string     // Comma separated tags
+	Viewable    string     // "public" or "private"
+	ViewableAt  *time.Time // When a private video becomes public
 }
 
 //CreateVideoOutput create the skeleton for a video upload
@@ -35,10 +50,11 @@ type CompleteVideoOutput struct{}
 
 //UploadVideoPartInput the parameters used to upload a video part
 type UploadVideoPartInput struct {
-	VideoID string
-	Token   string
-	Part    int
-	Body    *bytes.Buffer
+	VideoID  string
+	Token    string
+	Part     int
+	Body     *bytes.Buffer
+	Progress ProgressFunc // Called as the part is sent, optional
 }
 
 //UploadVideoPartOutput output from upoading a video part
@@ -49,25 +65,79 @@ type UploadVideoPartOutput struct {
 
 // CreateVideo - Create the skeleton for a video to upload the content to
 func (c *Client) CreateVideo(input *CreateVideoInput) (*CreateVideoOutput, *ErrorOutput) {
+	return c.CreateVideoWithContext(context.Background(), input)
+}
+
+// CreateVideoWithContext - the same as CreateVideo but the request is bound to the context
+func (c *Client) CreateVideoWithContext(ctx context.Context, input *CreateVideoInput) (*CreateVideoOutput, *ErrorOutput) {
 	output := new(CreateVideoOutput)
+	if c.apiVersion >= 5 && input.ChannelID == 0 {
+		// Not a TransportError, sending it again won't help
+		return output, &ErrorOutput{
+			Message: ErrMissingChannelID.Error(),
+			Error:   "Twitchy error",
+			Status:  -1,
+			err:     ErrMissingChannelID,
+		}
+	}
 	params := map[string]string{}
-	params["channel_name"] = input.ChannelName
-	//V5 params["channel_id"] = strconv.FormatInt(input.ChannelID, 10)
+	if input.ChannelID != 0 {
+		params["channel_id"] = strconv.FormatInt(input.ChannelID, 10)
+	}
+	if c.apiVersion < 5 && input.ChannelName != "" {
+		params["channel_name"] = input.ChannelName
+	}
 	params["title"] = input.Title
-	errorOutput := c.sendAPIRequest("POST", "videos", params, output)
+	if input.Description != "" {
+		params["description"] = input.Description
+	}
+	if input.Game != "" {
+		params["game"] = input.Game
+	}
+	if input.Language != "" {
+		params["language"] = input.Language
+	}
+	if input.TagList != "" {
+		params["tag_list"] = input.TagList
+	}
+	if input.Viewable != "" {
+		params["viewable"] = input.Viewable
+	}
+	if input.ViewableAt != nil {
+		params["viewable_at"] = input.ViewableAt.UTC().Format(time.RFC3339)
+	}
+	errorOutput := c.sendAPIRequest(ctx, "CreateVideo", "POST", "videos", params, output)
 	return output, errorOutput
 }
 
 // UploadVideoPart - Upload a video part
 func (c *Client) UploadVideoPart(input *UploadVideoPartInput) (*UploadVideoPartOutput, *ErrorOutput) {
+	return c.UploadVideoPartWithContext(context.Background(), input)
+}
+
+// UploadVideoPartWithContext - the same as UploadVideoPart but the request is bound to the context
+func (c *Client) UploadVideoPartWithContext(ctx context.Context, input *UploadVideoPartInput) (*UploadVideoPartOutput, *ErrorOutput) {
 	output := new(UploadVideoPartOutput)
-	errorOutput := c.sendUploadRequest("PUT", fmt.Sprintf("upload/%s?upload_token=%s&part=%d", strings.Replace(input.VideoID, "v", "", 1), input.Token, input.Part), "", input.Body, output)
+	partBytes := int64(input.Body.Len())
+	progress := newProgressTracker(input.Progress, c.now, partBytes, 1)
+	if progress != nil {
+		ctx = withBodyWrapper(ctx, progress.wrap(input.Part, partBytes))
+	}
+	errorOutput := c.sendUploadRequest(ctx, "UploadVideoPart", "PUT", fmt.Sprintf("upload/%s?upload_token=%s&part=%d", strings.Replace(input.VideoID, "v", "", 1), input.Token, input.Part), "", input.Body, output)
+	if progress != nil && errorOutput == nil {
+		progress.completed(input.Part, partBytes)
+	}
 	return output, errorOutput
 }
 
 // CompleteVideo - Complete a video upload
 func (c *Client) CompleteVideo(input *CompleteVideoInput) (*CompleteVideoOutput, *ErrorOutput) {
+	return c.CompleteVideoWithContext(context.Background(), input)
+}
+
+// CompleteVideoWithContext - the same as CompleteVideo but the request is bound to the context
+func (c *Client) CompleteVideoWithContext(ctx context.Context, input *CompleteVideoInput) (*CompleteVideoOutput, *ErrorOutput) {
 	output := new(CompleteVideoOutput)
-	errorOutput := c.sendUploadRequest("POST", fmt.Sprintf("upload/%s/complete?upload_token=%s", strings.Replace(input.VideoID, "v", "", 1), input.Token), "application/x-www-form-urlencoded", bytes.NewBuffer([]byte{}), output)
+	errorOutput := c.sendUploadRequest(ctx, "CompleteVideo", "POST", fmt.Sprintf("upload/%s/complete?upload_token=%s", strings.Replace(input.VideoID, "v", "", 1), input.Token), "application/x-www-form-urlencoded", bytes.NewBuffer([]byte{}), output)
 	return output, errorOutput
 }
@@ -1,8 +1,12 @@
 package twitch
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 )
@@ -17,9 +21,8 @@ func TestCreateVideo(t *testing.T) {
 	client := NewClient(&OAuthConfig{}, &http.Client{})
 
 	output, errorOutput := client.CreateVideo(&CreateVideoInput{
-		ChannelName: "ollieparsleydev",
-		//V5 ChannelID: 139985889,
-		Title: "Test upload",
+		ChannelID: 139985889,
+		Title:     "Test upload",
 	})
 
 	if errorOutput != nil {
@@ -44,6 +47,138 @@ func TestCreateVideo(t *testing.T) {
 
 }
 
+func TestCreateVideoMetadata(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var form url.Values
+	httpmock.RegisterResponder("POST", "https://api.twitch.tv/kraken/videos",
+		func(req *http.Request) (*http.Response, error) {
+			form = requestForm(req)
+			return httpmock.NewStringResponse(200, `{"upload":{"url":"https://uploads.twitch.tv/upload/123456","token":"this-is-a-token"},"video":{"_id":"v123456","title":"Test upload","description":"Highlights","game":"Nioh","language":"en","tag_list":"nioh,speedrun","viewable":"private","viewable_at":"2017-07-14T02:40:00Z"}}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{})
+	viewableAt := time.Date(2017, 7, 14, 2, 40, 0, 0, time.UTC)
+
+	output, errorOutput := client.CreateVideo(&CreateVideoInput{
+		ChannelID:   139985889,
+		Title:       "Test upload",
+		Description: "Highlights",
+		Game:        "Nioh",
+		Language:    "en",
+		TagList:     "nioh,speedrun",
+		Viewable:    "private",
+		ViewableAt:  &viewableAt,
+	})
+
+	if errorOutput != nil {
+		t.Errorf("CreateVideo errorOutput should have been nil: %+v", errorOutput)
+	}
+	expected := map[string]string{
+		"channel_id":  "139985889",
+		"title":       "Test upload",
+		"description": "Highlights",
+		"game":        "Nioh",
+		"language":    "en",
+		"tag_list":    "nioh,speedrun",
+		"viewable":    "private",
+		"viewable_at": "2017-07-14T02:40:00Z",
+	}
+	for name, value := range expected {
+		if form.Get(name) != value {
+			t.Errorf("CreateVideo the %s was not \"%s\": %v", name, value, form)
+		}
+	}
+	if _, ok := form["channel_name"]; ok {
+		t.Errorf("CreateVideo the channel name should not have been sent: %v", form)
+	}
+	if output.Video.Description != "Highlights" || output.Video.TagList != "nioh,speedrun" || output.Video.ViewableAt == nil || !output.Video.ViewableAt.Equal(viewableAt) {
+		t.Errorf("CreateVideo the metadata was not returned: %+v", output.Video)
+	}
+}
+
+func TestCreateVideoChannelName(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var form url.Values
+	httpmock.RegisterResponder("POST", "https://api.twitch.tv/kraken/videos",
+		func(req *http.Request) (*http.Response, error) {
+			form = requestForm(req)
+			return httpmock.NewStringResponse(200, `{"upload":{},"video":{"_id":"v123456"}}`), nil
+		})
+
+	client := NewClient(&OAuthConfig{}, &http.Client{}, WithAPIVersion(4))
+	client.CreateVideo(&CreateVideoInput{ChannelName: "ollieparsleydev", Title: "Test upload"})
+
+	if form.Get("channel_name") != "ollieparsleydev" {
+		t.Errorf("CreateVideo the channel name was not sent: %v", form)
+	}
+	for _, name := range []string{"channel_id", "description", "game", "language", "tag_list", "viewable", "viewable_at"} {
+		if _, ok := form[name]; ok {
+			t.Errorf("CreateVideo the %s should not have been sent: %v", name, form)
+		}
+	}
+}
+
+func TestCreateVideoChannel(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var form url.Values
+	httpmock.RegisterResponder("POST", "https://api.twitch.tv/kraken/videos",
+		func(req *http.Request) (*http.Response, error) {
+			form = requestForm(req)
+			return httpmock.NewStringResponse(200, `{"upload":{},"video":{"_id":"v123456"}}`), nil
+		})
+
+	tests := []struct {
+		version     int
+		channelID   int64
+		channelName string
+		sent        url.Values // nil when the video can't be created
+	}{
+		{4, 0, "ollieparsleydev", url.Values{"channel_name": {"ollieparsleydev"}}},
+		{4, 139985889, "", url.Values{"channel_id": {"139985889"}}},
+		{4, 139985889, "ollieparsleydev", url.Values{"channel_id": {"139985889"}, "channel_name": {"ollieparsleydev"}}},
+		{4, 0, "", url.Values{}},
+		{5, 0, "ollieparsleydev", nil},
+		{5, 139985889, "", url.Values{"channel_id": {"139985889"}}},
+		{5, 139985889, "ollieparsleydev", url.Values{"channel_id": {"139985889"}}},
+		{5, 0, "", nil},
+	}
+	for _, test := range tests {
+		form = nil
+		client := NewClient(&OAuthConfig{}, &http.Client{}, WithAPIVersion(test.version))
+
+		_, errorOutput := client.CreateVideo(&CreateVideoInput{ChannelID: test.channelID, ChannelName: test.channelName})
+
+		if test.sent == nil {
+			if !errors.Is(errorOutput.Err(), ErrMissingChannelID) {
+				t.Errorf("CreateVideo v%d %d %q the missing channel ID error was not returned: %v", test.version, test.channelID, test.channelName, errorOutput.Err())
+			}
+			var transportError *TransportError
+			if errors.As(errorOutput.Err(), &transportError) {
+				t.Errorf("CreateVideo the missing channel ID should not have been a transport error: %v", errorOutput.Err())
+			}
+			if form != nil {
+				t.Errorf("CreateVideo v%d %d %q the video should not have been created: %v", test.version, test.channelID, test.channelName, form)
+			}
+			continue
+		}
+		sent := url.Values{}
+		for _, name := range []string{"channel_id", "channel_name"} {
+			if value, ok := form[name]; ok {
+				sent[name] = value
+			}
+		}
+		if !reflect.DeepEqual(sent, test.sent) {
+			t.Errorf("CreateVideo v%d %d %q the channel sent was not %v: %v", test.version, test.channelID, test.channelName, test.sent, sent)
+		}
+	}
+}
+
 /*func TestUploadVideoPart(t *testing.T) {
 	client := NewClient(&OAuthConfig{
 		ClientID:    "pzqv1a6n4r1l7wzto3mor00bzkpmw8c",
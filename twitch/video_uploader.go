@@ -0,0 +1,333 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// The sizes Twitch accepts for each part of a video upload, only the last part can be smaller
+const (
+	MinPartSize     = 5 * 1024 * 1024
+	MaxPartSize     = 25 * 1024 * 1024
+	DefaultPartSize = 10 * 1024 * 1024
+)
+
+// Errors returned before an upload is started
+var (
+	// ErrInvalidPartSize the part size is outside the sizes Twitch accepts
+	ErrInvalidPartSize = fmt.Errorf("twitch: the part size must be between %d and %d bytes", MinPartSize, MaxPartSize)
+	// ErrEmptyVideo there was nothing to upload
+	ErrEmptyVideo = errors.New("twitch: the video is empty")
+)
+
+//UploadError uploading a video failed, VideoID is empty when the video wasn't created
+type UploadError struct {
+	VideoID string
+	Part    int // 0 when the failure wasn't uploading a part
+	Err     error
+}
+
+// Error - the part that failed and the cause
+func (e *UploadError) Error() string {
+	if e.Part == 0 {
+		return "twitch: uploading video " + e.VideoID + " failed: " + e.Err.Error()
+	}
+	return fmt.Sprintf("twitch: uploading part %d of video %s failed: %s", e.Part, e.VideoID, e.Err)
+}
+
+// Unwrap - the cause, e.g. an *APIError
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+//VideoUploader uploads a whole video: it creates the video, uploads the content part by part and completes it.
+//Each worker holds one part in memory.
+type VideoUploader struct {
+	PartSize  int               // Bytes in each part, DefaultPartSize when 0
+	Workers   int               // Parts uploaded at the same time, 1 when 0
+	PartRetry *RetryPolicy      // Retries of a failed part on top of the client's retries, nil to not retry
+	Progress  ProgressFunc      // Called as the parts are sent, optional
+	Bandwidth *BandwidthLimiter // Caps the bandwidth of this upload on top of the client's cap, optional
+	Limits    *VideoLimits      // Video files are checked against these before the video is created, DefaultVideoLimits when nil
+
+	client *Client
+}
+
+//partReader reads the next part to upload into the buffer, returning part 0 when there are no parts left
+type partReader func(buffer []byte) (part int, data []byte, err error)
+
+// NewVideoUploader - create an uploader that uses the client
+func (c *Client) NewVideoUploader() *VideoUploader {
+	return &VideoUploader{
+		PartSize: DefaultPartSize,
+		Workers:  1,
+		client:   c,
+	}
+}
+
+// UploadFile - upload the video file at path
+func (u *VideoUploader) UploadFile(ctx context.Context, video *CreateVideoInput, path string) (*Video, error) {
+	return u.traceUpload(ctx, "VideoUploader.UploadFile", func(ctx context.Context) (*Video, error) {
+		return u.uploadFile(ctx, video, path)
+	})
+}
+
+//uploadFile validate and upload the video file at path
+func (u *VideoUploader) uploadFile(ctx context.Context, video *CreateVideoInput, path string) (*Video, error) {
+	if err := u.validate(path); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return u.upload(ctx, video, file)
+}
+
+// Upload - create the video, upload everything read from body and complete the video.
+// The first part is read before the video is created so an unreadable or empty body doesn't leave an empty video behind.
+func (u *VideoUploader) Upload(ctx context.Context, video *CreateVideoInput, body io.Reader) (*Video, error) {
+	return u.traceUpload(ctx, "VideoUploader.Upload", func(ctx context.Context) (*Video, error) {
+		return u.upload(ctx, video, body)
+	})
+}
+
+//upload create the video, upload body in parts and complete the video
+func (u *VideoUploader) upload(ctx context.Context, video *CreateVideoInput, body io.Reader) (*Video, error) {
+	partSize, err := u.partSize()
+	if err != nil {
+		return nil, err
+	}
+
+	first := make([]byte, partSize)
+	n, err := io.ReadFull(body, first)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, &UploadError{Part: 1, Err: err}
+	}
+	if n == 0 {
+		return nil, ErrEmptyVideo
+	}
+	first = first[:n]
+
+	created, errorOutput := u.client.CreateVideoWithContext(ctx, video)
+	if errorOutput != nil {
+		return nil, &UploadError{Err: errorOutput.Err()}
+	}
+	videoID, token := created.Video.ID, created.Upload.Token
+
+	var progress *progressTracker
+	if size := readerSize(body); size >= 0 {
+		size += int64(n)
+		progress = newProgressTracker(u.Progress, u.client.now, size, int((size+int64(partSize)-1)/int64(partSize)))
+	} else {
+		progress = newProgressTracker(u.Progress, u.client.now, 0, 0)
+	}
+
+	part, last := 0, false
+	next := func(buffer []byte) (int, []byte, error) {
+		if last {
+			return 0, nil, nil
+		}
+		part++
+		if part == 1 {
+			n, first = copy(buffer, first), nil
+			last = n < partSize
+			return part, buffer[:n], nil
+		}
+
+		n, err := io.ReadFull(body, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, nil, &UploadError{VideoID: videoID, Part: part, Err: err}
+		}
+		last = n < partSize
+		if n == 0 {
+			return 0, nil, nil
+		}
+		return part, buffer[:n], nil
+	}
+	if err := u.uploadParts(ctx, videoID, token, partSize, next, progress, nil); err != nil {
+		return nil, err
+	}
+
+	_, errorOutput = u.client.CompleteVideoWithContext(ctx, &CompleteVideoInput{VideoID: videoID, Token: token})
+	if errorOutput != nil {
+		return nil, &UploadError{VideoID: videoID, Err: errorOutput.Err()}
+	}
+	return &created.Video, nil
+}
+
+//traceUpload make the endpoint calls of the upload inside one span named after it
+func (u *VideoUploader) traceUpload(ctx context.Context, name string, upload func(ctx context.Context) (*Video, error)) (*Video, error) {
+	ctx, span := u.client.startSpan(ctx, name, nil)
+	output, err := upload(ctx)
+	endSpan(span, nil, err)
+	return output, err
+}
+
+//uploadParts upload the parts of partSize bytes from next with up to Workers parts in flight, reporting them to progress
+//when it isn't nil. done, when not nil, is called after each part is uploaded, never for two parts at the same time.
+//The first failure cancels the parts in flight, no more parts are read and the failure is returned.
+func (u *VideoUploader) uploadParts(ctx context.Context, videoID string, token string, partSize int, next partReader, progress *progressTracker, done func(part int) error) error {
+	workers := u.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	// A part is read into a free buffer, the worker that uploads it gives the buffer back
+	type job struct {
+		part int
+		data []byte
+	}
+	jobs := make(chan job)
+	buffers := make(chan []byte, workers)
+	for i := 0; i < workers; i++ {
+		buffers <- make([]byte, partSize)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				err := u.uploadPart(ctx, videoID, token, job.part, job.data, progress)
+				buffers <- job.data[:cap(job.data)]
+				if err == nil && done != nil {
+					mu.Lock()
+					if firstErr == nil {
+						err = done(job.part)
+					}
+					mu.Unlock()
+				}
+				if err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	for ctx.Err() == nil {
+		var buffer []byte
+		select {
+		case buffer = <-buffers:
+		case <-ctx.Done():
+		}
+		if buffer == nil {
+			break
+		}
+		part, data, err := next(buffer)
+		if err != nil {
+			fail(err)
+			break
+		}
+		if part == 0 {
+			break
+		}
+		select {
+		case jobs <- job{part: part, data: data}:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr == nil && parent.Err() != nil {
+		return parent.Err()
+	}
+	return firstErr
+}
+
+//uploadPart upload one part, retrying it with PartRetry
+func (u *VideoUploader) uploadPart(ctx context.Context, videoID string, token string, part int, data []byte, progress *progressTracker) error {
+	if progress != nil {
+		ctx = withBodyWrapper(ctx, progress.wrap(part, int64(len(data))))
+	}
+	if u.Bandwidth != nil {
+		ctx = withBodyWrapper(ctx, u.Bandwidth.wrap(ctx))
+	}
+	for attempt := 1; ; attempt++ {
+		var metadata ResponseMetadata
+		_, errorOutput := u.client.UploadVideoPartWithContext(WithResponseMetadata(ctx, &metadata), &UploadVideoPartInput{
+			VideoID: videoID,
+			Token:   token,
+			Part:    part,
+			Body:    bytes.NewBuffer(data),
+		})
+		if errorOutput == nil {
+			if progress != nil {
+				progress.completed(part, int64(len(data)))
+			}
+			return nil
+		}
+		retry := u.PartRetry
+		if retry == nil || attempt >= retry.MaxAttempts || ctx.Err() != nil || !retry.retryableError(errorOutput) {
+			return &UploadError{VideoID: videoID, Part: part, Err: errorOutput.Err()}
+		}
+		if err := sleepContext(ctx, retry.delay(attempt, metadata.Header, u.client.now())); err != nil {
+			return &UploadError{VideoID: videoID, Part: part, Err: err}
+		}
+	}
+}
+
+//validate check the video file is a video Twitch accepts within the Limits
+func (u *VideoUploader) validate(path string) error {
+	limits := DefaultVideoLimits()
+	if u.Limits != nil {
+		limits = *u.Limits
+	}
+	_, err := ValidateVideoFile(path, limits)
+	return err
+}
+
+//partSize the part size to use, checked against the sizes Twitch accepts
+func (u *VideoUploader) partSize() (int, error) {
+	if u.PartSize == 0 {
+		return DefaultPartSize, nil
+	}
+	if u.PartSize < MinPartSize || u.PartSize > MaxPartSize {
+		return 0, ErrInvalidPartSize
+	}
+	return u.PartSize, nil
+}
+
+//readerSize the bytes left to read from the reader, -1 when unknown
+func readerSize(r io.Reader) int64 {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
@@ -0,0 +1,304 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeUploads a stand-in for the video endpoints of the Twitch API and upload service
+type fakeUploads struct {
+	mu        sync.Mutex
+	created   int
+	parts     map[int][]byte
+	failures  map[int]int // the number of times uploading each part fails before it succeeds
+	completed bool
+	tokens    []string
+
+	delay       time.Duration // how long each part takes to upload
+	inFlight    int
+	maxInFlight int
+}
+
+func newFakeUploads() *fakeUploads {
+	return &fakeUploads{parts: map[int][]byte{}, failures: map[int]int{}}
+}
+
+func (f *fakeUploads) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "PUT" {
+		f.mu.Lock()
+		f.inFlight++
+		if f.inFlight > f.maxInFlight {
+			f.maxInFlight = f.inFlight
+		}
+		f.mu.Unlock()
+		defer func() {
+			f.mu.Lock()
+			f.inFlight--
+			f.mu.Unlock()
+		}()
+		time.Sleep(f.delay)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/kraken/videos":
+		f.created++
+		w.Write([]byte(`{"upload":{"url":"https://uploads.twitch.tv/upload/123456","token":"upload-token"},"video":{"_id":"v123456","title":"` + requestForm(r).Get("title") + `","status":"created"}}`))
+	case r.Method == "PUT" && r.URL.Path == "/upload/123456":
+		body, _ := ioutil.ReadAll(r.Body)
+		part, _ := strconv.Atoi(r.URL.Query().Get("part"))
+		f.tokens = append(f.tokens, r.URL.Query().Get("upload_token"))
+		if f.failures[part] > 0 {
+			f.failures[part]--
+			w.WriteHeader(500)
+			w.Write([]byte(`{"error":"Internal Server Error","status":500,"message":""}`))
+			return
+		}
+		f.parts[part] = body
+		w.WriteHeader(200)
+	case r.Method == "POST" && r.URL.Path == "/upload/123456/complete":
+		f.completed = true
+		w.WriteHeader(200)
+	default:
+		w.WriteHeader(404)
+		w.Write([]byte(`{"error":"Not Found","status":404,"message":""}`))
+	}
+}
+
+// content the parts that were uploaded, joined in order
+func (f *fakeUploads) content() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content := []byte{}
+	for part := 1; part <= len(f.parts); part++ {
+		content = append(content, f.parts[part]...)
+	}
+	return content
+}
+
+func newFakeUploadClient(server *httptest.Server) *Client {
+	return NewClient(&OAuthConfig{ClientID: "client-id", AccessToken: "access-token"}, nil,
+		WithAPIURL(server.URL+"/kraken/"),
+		WithUploadURL(server.URL+"/"),
+	)
+}
+
+//randomVideo an MP4 of size bytes with random media data, so uploaders accept it, smaller than the headers it is only random
+func randomVideo(size int) []byte {
+	video := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(video)
+	header := append(mp4Box("ftyp", []byte("isom"), make([]byte, 4)), mp4Box("moov", mp4Box("mvhd", make([]byte, 100)))...)
+	if size < len(header)+8 {
+		return video
+	}
+	copy(video, header)
+	binary.BigEndian.PutUint32(video[len(header):], uint32(size-len(header)))
+	copy(video[len(header)+4:], "mdat")
+	return video
+}
+
+func TestVideoUploaderUpload(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	video := randomVideo(2*MinPartSize + 1234)
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+
+	output, err := uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, bytes.NewReader(video))
+
+	if err != nil {
+		t.Fatalf("Upload err should have been nil: %s", err)
+	}
+	if output.ID != "v123456" || output.Title != "Test" {
+		t.Errorf("Upload the created video was not returned: %+v", output)
+	}
+	if len(uploads.parts) != 3 {
+		t.Errorf("Upload the video was not split into 3 parts: %d", len(uploads.parts))
+	}
+	if len(uploads.parts[1]) != MinPartSize || len(uploads.parts[3]) != 1234 {
+		t.Errorf("Upload the parts were not the right size: %d %d", len(uploads.parts[1]), len(uploads.parts[3]))
+	}
+	if !bytes.Equal(uploads.content(), video) {
+		t.Error("Upload the uploaded parts were not the video")
+	}
+	if !uploads.completed {
+		t.Error("Upload the video was not completed")
+	}
+	for _, token := range uploads.tokens {
+		if token != "upload-token" {
+			t.Errorf("Upload the upload token was not sent: %s", token)
+		}
+	}
+}
+
+func TestVideoUploaderUploadExactParts(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	video := randomVideo(2 * MinPartSize)
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+
+	_, err := uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, bytes.NewReader(video))
+
+	if err != nil {
+		t.Fatalf("Upload err should have been nil: %s", err)
+	}
+	if len(uploads.parts) != 2 {
+		t.Errorf("Upload an empty last part should not have been sent: %d", len(uploads.parts))
+	}
+}
+
+func TestVideoUploaderUploadFile(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "twitch-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	video := randomVideo(MinPartSize + 10)
+	path := filepath.Join(dir, "video.mp4")
+	ioutil.WriteFile(path, video, 0600)
+
+	_, err = newFakeUploadClient(server).NewVideoUploader().UploadFile(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, path)
+
+	if err != nil {
+		t.Fatalf("UploadFile err should have been nil: %s", err)
+	}
+	if !bytes.Equal(uploads.content(), video) {
+		t.Error("UploadFile the uploaded parts were not the file")
+	}
+}
+
+func TestVideoUploaderErrors(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+
+	_, err := uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234}, strings.NewReader(""))
+	if err != ErrEmptyVideo {
+		t.Errorf("Upload the empty video error was not returned: %v", err)
+	}
+	if uploads.created != 0 {
+		t.Errorf("Upload an empty video should not have been created: %d", uploads.created)
+	}
+
+	uploader.PartSize = MaxPartSize + 1
+	_, err = uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234}, strings.NewReader("video"))
+	if err != ErrInvalidPartSize {
+		t.Errorf("Upload the invalid part size error was not returned: %v", err)
+	}
+
+	uploader.PartSize = MinPartSize
+	uploads.failures[2] = 1
+	_, err = uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234}, bytes.NewReader(randomVideo(MinPartSize+1)))
+	var uploadError *UploadError
+	if !errors.As(err, &uploadError) || uploadError.Part != 2 || uploadError.VideoID != "v123456" {
+		t.Errorf("Upload the failed part was not returned: %v", err)
+	}
+	if !errors.Is(err, ErrServer) {
+		t.Errorf("Upload the cause was not a server error: %v", err)
+	}
+	if uploads.completed {
+		t.Error("Upload the video should not have been completed")
+	}
+}
+
+func TestVideoUploaderWorkers(t *testing.T) {
+	uploads := newFakeUploads()
+	uploads.delay = 200 * time.Millisecond
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	video := randomVideo(3*MinPartSize + 10)
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+	uploader.Workers = 3
+
+	_, err := uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, bytes.NewReader(video))
+
+	if err != nil {
+		t.Fatalf("Upload err should have been nil: %s", err)
+	}
+	if uploads.maxInFlight != 3 {
+		t.Errorf("Upload the parts were not uploaded 3 at a time: %d", uploads.maxInFlight)
+	}
+	if !bytes.Equal(uploads.content(), video) || !uploads.completed {
+		t.Error("Upload the video was not uploaded")
+	}
+}
+
+func TestVideoUploaderPartRetry(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	video := randomVideo(2*MinPartSize + 10)
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+	uploader.Workers = 2
+	uploader.PartRetry = &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, RetryableStatuses: []int{500}}
+	uploads.failures[2] = 2
+
+	_, err := uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, bytes.NewReader(video))
+
+	if err != nil {
+		t.Fatalf("Upload err should have been nil: %s", err)
+	}
+	if len(uploads.tokens) != 5 {
+		t.Errorf("Upload the failed part was not retried twice: %d", len(uploads.tokens))
+	}
+	if !bytes.Equal(uploads.content(), video) || !uploads.completed {
+		t.Error("Upload the video was not uploaded")
+	}
+}
+
+func TestVideoUploaderWorkersFailure(t *testing.T) {
+	uploads := newFakeUploads()
+	uploads.delay = 50 * time.Millisecond
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+	uploader.PartSize = MinPartSize
+	uploader.Workers = 2
+	uploads.failures[1] = 1
+
+	_, err := uploader.Upload(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, bytes.NewReader(randomVideo(6*MinPartSize)))
+	server.Close() // wait for the cancelled parts
+
+	var uploadError *UploadError
+	if !errors.As(err, &uploadError) || uploadError.Part != 1 {
+		t.Errorf("Upload the failed part was not returned: %v", err)
+	}
+	if len(uploads.tokens) >= 6 {
+		t.Errorf("Upload the remaining parts were not cancelled: %d", len(uploads.tokens))
+	}
+	if uploads.completed {
+		t.Error("Upload the video should not have been completed")
+	}
+}
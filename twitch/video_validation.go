@@ -0,0 +1,325 @@
+package twitch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// incompleteVideo the problem with a file that has been cut short
+const incompleteVideo = "the file ends early, it may be incomplete"
+
+// The video containers Twitch accepts that can be checked before uploading
+const (
+	VideoFormatMP4 = "mp4"
+	VideoFormatMOV = "mov"
+	VideoFormatFLV = "flv"
+)
+
+//VideoLimits what a video file must be within to be uploaded, a zero value isn't checked
+type VideoLimits struct {
+	MaxSize     int64
+	MaxDuration time.Duration
+}
+
+// DefaultVideoLimits - the limits of Twitch video uploads, 10GB per file.
+// Twitch doesn't publish a maximum duration, set MaxDuration on the copy returned to enforce one.
+func DefaultVideoLimits() VideoLimits {
+	return VideoLimits{
+		MaxSize: 10 * 1000 * 1000 * 1000,
+	}
+}
+
+//VideoInfo what was read from the headers of a video file, Duration is 0 when the headers don't say
+type VideoInfo struct {
+	Format   string
+	Size     int64
+	Duration time.Duration
+}
+
+//ValidationError a video file that can't be uploaded, with everything that is wrong with it
+type ValidationError struct {
+	Path     string
+	Problems []string
+}
+
+// Error - the file and its problems
+func (e *ValidationError) Error() string {
+	return "twitch: " + e.Path + " can't be uploaded: " + strings.Join(e.Problems, ", ")
+}
+
+// ValidateVideoFile - check the video file at path is a container Twitch accepts and is within the limits,
+// a *ValidationError lists the problems. Only the headers are read.
+func ValidateVideoFile(path string, limits VideoLimits) (*VideoInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	info, problems, err := probeVideo(file, stat.Size())
+	if err != nil {
+		return nil, err
+	}
+	if info.Size == 0 {
+		problems = append(problems, "the file is empty")
+	}
+	if limits.MaxSize > 0 && info.Size > limits.MaxSize {
+		problems = append(problems, fmt.Sprintf("the file is %d bytes, more than the %d allowed", info.Size, limits.MaxSize))
+	}
+	if limits.MaxDuration > 0 && info.Duration > limits.MaxDuration {
+		problems = append(problems, fmt.Sprintf("the video is %s long, more than the %s allowed", info.Duration, limits.MaxDuration))
+	}
+	if len(problems) > 0 {
+		return info, &ValidationError{Path: path, Problems: problems}
+	}
+	return info, nil
+}
+
+//probeVideo read the container headers, problems are what makes the file unusable and err is a failure to read it
+func probeVideo(r io.ReaderAt, size int64) (*VideoInfo, []string, error) {
+	info := &VideoInfo{Size: size}
+	if size == 0 {
+		return info, nil, nil
+	}
+
+	header := make([]byte, 12)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	header = header[:n]
+
+	var problems []string
+	switch {
+	case bytes.HasPrefix(header, []byte("FLV")):
+		info.Format = VideoFormatFLV
+		problems, err = probeFLV(r, size, info)
+	case len(header) >= 8 && isMP4Box(string(header[4:8])):
+		problems, err = probeMP4(r, size, info)
+	default:
+		return info, []string{"the file is not an MP4, MOV or FLV video"}, nil
+	}
+
+	// The headers point past the end of the file
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return info, []string{incompleteVideo}, nil
+	}
+	return info, problems, err
+}
+
+//isMP4Box whether the type is a box that starts MP4 and MOV files
+func isMP4Box(boxType string) bool {
+	switch boxType {
+	case "ftyp", "moov", "mdat", "wide", "free", "skip":
+		return true
+	}
+	return false
+}
+
+//probeMP4 read the brand from the ftyp box and the duration from the mvhd box in the moov box
+func probeMP4(r io.ReaderAt, size int64, info *VideoInfo) ([]string, error) {
+	info.Format = VideoFormatMOV
+	var moov, moovSize int64
+	for offset := int64(0); offset < size; {
+		boxType, headerSize, boxSize, err := readMP4Box(r, offset, size)
+		if err != nil {
+			return nil, err
+		}
+		if boxSize < headerSize {
+			return []string{"the MP4 boxes are corrupt"}, nil
+		}
+		if boxSize > size-offset {
+			return []string{incompleteVideo}, nil
+		}
+		switch boxType {
+		case "ftyp":
+			brand := make([]byte, 4)
+			if _, err := r.ReadAt(brand, offset+headerSize); err != nil {
+				return nil, err
+			}
+			if string(brand) != "qt  " {
+				info.Format = VideoFormatMP4
+			}
+		case "moov":
+			moov, moovSize = offset+headerSize, boxSize-headerSize
+		}
+		offset += boxSize
+	}
+	if moov == 0 {
+		return []string{"the video has no movie header, it may be incomplete"}, nil
+	}
+
+	for offset := moov; offset < moov+moovSize; {
+		boxType, headerSize, boxSize, err := readMP4Box(r, offset, moov+moovSize)
+		if err != nil {
+			return nil, err
+		}
+		if boxSize < headerSize || boxSize > moov+moovSize-offset {
+			return []string{"the MP4 boxes are corrupt"}, nil
+		}
+		if boxType == "mvhd" {
+			return nil, readMVHD(r, offset+headerSize, info)
+		}
+		offset += boxSize
+	}
+	return []string{"the video has no movie header, it may be incomplete"}, nil
+}
+
+//readMP4Box read the type and size of the box at offset, a box that runs to the end is given the size up to end
+func readMP4Box(r io.ReaderAt, offset int64, end int64) (string, int64, int64, error) {
+	header := make([]byte, 16)
+	if _, err := r.ReadAt(header[:8], offset); err != nil {
+		return "", 0, 0, err
+	}
+	boxType := string(header[4:8])
+	switch boxSize := int64(binary.BigEndian.Uint32(header)); boxSize {
+	case 0:
+		return boxType, 8, end - offset, nil
+	case 1:
+		if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+			return "", 0, 0, err
+		}
+		return boxType, 16, int64(binary.BigEndian.Uint64(header[8:16])), nil
+	default:
+		return boxType, 8, boxSize, nil
+	}
+}
+
+//readMVHD read the duration from the movie header, its layout depends on the version
+func readMVHD(r io.ReaderAt, offset int64, info *VideoInfo) error {
+	mvhd := make([]byte, 32)
+	if _, err := r.ReadAt(mvhd, offset); err != nil {
+		return err
+	}
+	var timescale, duration uint64
+	if mvhd[0] == 1 {
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	}
+	if timescale > 0 {
+		info.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+	}
+	return nil
+}
+
+//probeFLV read the duration from the onMetaData script tag, or from the timestamp of the last tag when there isn't one
+func probeFLV(r io.ReaderAt, size int64, info *VideoInfo) ([]string, error) {
+	// The header, the first previous tag size and a tag
+	if size < 24 {
+		return []string{incompleteVideo}, nil
+	}
+	header := make([]byte, 9)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if header[4]&0x05 == 0 {
+		return []string{"the FLV has no audio or video"}, nil
+	}
+
+	// The first tag follows the header and a 4 byte previous tag size
+	offset := int64(binary.BigEndian.Uint32(header[5:9])) + 4
+	tag := make([]byte, 11)
+	if _, err := r.ReadAt(tag, offset); err == nil && tag[0] == 18 {
+		// A tag running past the end of the file is cut short, the duration is then read from the last tag
+		dataSize := int64(tag[1])<<16 | int64(tag[2])<<8 | int64(tag[3])
+		if dataSize <= size-offset-11 {
+			data := make([]byte, dataSize)
+			if _, err := r.ReadAt(data, offset+11); err == nil {
+				if duration, ok := amfDuration(data); ok {
+					// Durations that don't fit a time.Duration would pass any MaxDuration
+					if math.IsNaN(duration) || duration < 0 || duration > float64(math.MaxInt64/int64(time.Second)) {
+						return []string{"the duration in the FLV metadata is not valid"}, nil
+					}
+					info.Duration = time.Duration(duration * float64(time.Second))
+					return nil, nil
+				}
+			}
+		}
+	}
+
+	// The file ends with the size of the last tag
+	last := make([]byte, 4)
+	if _, err := r.ReadAt(last, size-4); err != nil {
+		return nil, err
+	}
+	lastTag := size - 4 - int64(binary.BigEndian.Uint32(last))
+	if lastTag < offset || lastTag+11 > size {
+		return []string{incompleteVideo}, nil
+	}
+	if _, err := r.ReadAt(tag, lastTag); err != nil {
+		return nil, err
+	}
+	timestamp := int64(tag[7])<<24 | int64(tag[4])<<16 | int64(tag[5])<<8 | int64(tag[6])
+	info.Duration = time.Duration(timestamp) * time.Millisecond
+	return nil, nil
+}
+
+//amfDuration find the duration number in the AMF0 encoded onMetaData, only the values before it need to be understood
+func amfDuration(data []byte) (float64, bool) {
+	// The "onMetaData" string then an ECMA array or an object
+	if len(data) < 3 || data[0] != 0x02 {
+		return 0, false
+	}
+	nameSize := 3 + int(binary.BigEndian.Uint16(data[1:3]))
+	if len(data) < nameSize {
+		return 0, false
+	}
+	data = data[nameSize:]
+	switch {
+	case len(data) >= 5 && data[0] == 0x08:
+		data = data[5:]
+	case len(data) >= 1 && data[0] == 0x03:
+		data = data[1:]
+	default:
+		return 0, false
+	}
+
+	for len(data) >= 3 {
+		keySize := int(binary.BigEndian.Uint16(data))
+		if len(data) < 2+keySize+1 {
+			return 0, false
+		}
+		key := string(data[2 : 2+keySize])
+		data = data[2+keySize:]
+		if key == "duration" && data[0] == 0x00 && len(data) >= 9 {
+			return math.Float64frombits(binary.BigEndian.Uint64(data[1:9])), true
+		}
+
+		var valueSize int
+		switch data[0] {
+		case 0x00: // number
+			valueSize = 9
+		case 0x01: // boolean
+			valueSize = 2
+		case 0x02: // string
+			if len(data) < 3 {
+				return 0, false
+			}
+			valueSize = 3 + int(binary.BigEndian.Uint16(data[1:3]))
+		case 0x05, 0x06: // null, undefined
+			valueSize = 1
+		case 0x0B: // date
+			valueSize = 11
+		default:
+			return 0, false
+		}
+		if len(data) < valueSize {
+			return 0, false
+		}
+		data = data[valueSize:]
+	}
+	return 0, false
+}
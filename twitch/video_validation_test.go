@@ -0,0 +1,197 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mp4Box(boxType string, content ...[]byte) []byte {
+	body := bytes.Join(content, nil)
+	box := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(box, uint32(8+len(body)))
+	copy(box[4:], boxType)
+	return append(box, body...)
+}
+
+// testMP4 - a file with the brand and a movie header of duration in timescale units
+func testMP4(brand string, timescale uint32, duration uint32) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:], duration)
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte(brand), make([]byte, 4)),
+		mp4Box("moov", mp4Box("mvhd", mvhd)),
+		mp4Box("mdat", make([]byte, 1000)),
+	}, nil)
+}
+
+func flvTag(tagType byte, timestamp uint32, data []byte) []byte {
+	tag := []byte{tagType, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data)),
+		byte(timestamp >> 16), byte(timestamp >> 8), byte(timestamp), byte(timestamp >> 24), 0, 0, 0}
+	tag = append(tag, data...)
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len(tag)))
+	return append(tag, size...)
+}
+
+// testFLV - a file with video tags up to lastTimestamp, with an onMetaData tag when duration isn't 0
+func testFLV(duration float64, lastTimestamp uint32) []byte {
+	flv := []byte{'F', 'L', 'V', 1, 0x05, 0, 0, 0, 9, 0, 0, 0, 0}
+	if duration != 0 {
+		metadata := []byte{0x02, 0, 10}
+		metadata = append(metadata, "onMetaData"...)
+		metadata = append(metadata, 0x08, 0, 0, 0, 3)
+		metadata = append(metadata, 0, 8)
+		metadata = append(metadata, "encoder1"...)
+		metadata = append(metadata, 0x02, 0, 3, 'o', 'b', 's')
+		metadata = append(metadata, 0, 6)
+		metadata = append(metadata, "stereo"...)
+		metadata = append(metadata, 0x01, 1)
+		metadata = append(metadata, 0, 8)
+		metadata = append(metadata, "duration"...)
+		number := make([]byte, 8)
+		binary.BigEndian.PutUint64(number, math.Float64bits(duration))
+		metadata = append(metadata, 0x00)
+		metadata = append(metadata, number...)
+		flv = append(flv, flvTag(18, 0, metadata)...)
+	}
+	flv = append(flv, flvTag(9, 0, make([]byte, 100))...)
+	return append(flv, flvTag(9, lastTimestamp, make([]byte, 100))...)
+}
+
+func writeTestVideo(t *testing.T, dir string, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateVideoFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "twitch-validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		content  []byte
+		format   string
+		duration time.Duration
+	}{
+		{"video.mp4", testMP4("isom", 1000, 90500), VideoFormatMP4, 90500 * time.Millisecond},
+		{"video.mov", testMP4("qt  ", 600, 1200), VideoFormatMOV, 2 * time.Second},
+		{"video.flv", testFLV(125.5, 3000), VideoFormatFLV, 125500 * time.Millisecond},
+		{"nometadata.flv", testFLV(0, 61000), VideoFormatFLV, 61 * time.Second},
+	}
+	for _, test := range tests {
+		info, err := ValidateVideoFile(writeTestVideo(t, dir, test.name, test.content), DefaultVideoLimits())
+		if err != nil {
+			t.Errorf("ValidateVideoFile %s err should have been nil: %s", test.name, err)
+			continue
+		}
+		if info.Format != test.format || info.Duration != test.duration || info.Size != int64(len(test.content)) {
+			t.Errorf("ValidateVideoFile %s the info was not correct: %+v", test.name, info)
+		}
+	}
+}
+
+func TestValidateVideoFileProblems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "twitch-validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mp4 := testMP4("isom", 1000, 7200000)
+	tests := []struct {
+		name    string
+		content []byte
+		limits  VideoLimits
+		problem string
+	}{
+		{"empty.mp4", []byte{}, DefaultVideoLimits(), "empty"},
+		{"video.txt", []byte("not a video at all"), DefaultVideoLimits(), "not an MP4, MOV or FLV"},
+		{"truncated.mp4", mp4[:len(mp4)-10], DefaultVideoLimits(), "incomplete"},
+		{"nomoov.mp4", mp4Box("ftyp", []byte("isom"), make([]byte, 4)), DefaultVideoLimits(), "no movie header"},
+		{"truncated.flv", testFLV(10, 10000)[:30], DefaultVideoLimits(), "incomplete"},
+		{"large.mp4", mp4, VideoLimits{MaxSize: 100}, "more than the 100 allowed"},
+		{"long.mp4", mp4, VideoLimits{MaxDuration: time.Hour}, "2h0m0s long"},
+		{"oversized.mp4", append(mp4Box("ftyp", []byte("isom"), make([]byte, 4)), 0xFF, 0xFF, 0xFF, 0xF0, 'm', 'd', 'a', 't'), DefaultVideoLimits(), "incomplete"},
+		{"largesize.mp4", append(mp4Box("ftyp", []byte("isom"), make([]byte, 4)), 0, 0, 0, 1, 'm', 'd', 'a', 't', 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF), DefaultVideoLimits(), "corrupt"},
+		{"moov.mp4", mp4Box("moov", []byte{0xFF, 0xFF, 0xFF, 0xF0, 'm', 'v', 'h', 'd'}), DefaultVideoLimits(), "corrupt"},
+		{"nan.flv", testFLV(math.NaN(), 1000), DefaultVideoLimits(), "duration in the FLV metadata is not valid"},
+		{"infinite.flv", testFLV(math.Inf(1), 1000), VideoLimits{MaxDuration: time.Hour}, "duration in the FLV metadata is not valid"},
+		{"negative.flv", testFLV(-10, 1000), DefaultVideoLimits(), "duration in the FLV metadata is not valid"},
+		{"tag.flv", append(testFLV(0, 0)[:13], 18, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0, 0, 0, 0, 0x02, 0, 10), DefaultVideoLimits(), "incomplete"},
+	}
+	for _, test := range tests {
+		_, err := ValidateVideoFile(writeTestVideo(t, dir, test.name, test.content), test.limits)
+		var validationError *ValidationError
+		if !errors.As(err, &validationError) {
+			t.Errorf("ValidateVideoFile %s the validation error was not returned: %v", test.name, err)
+			continue
+		}
+		if !strings.Contains(validationError.Error(), test.problem) {
+			t.Errorf("ValidateVideoFile %s the problem was not reported: %s", test.name, validationError)
+		}
+	}
+}
+
+func TestAMFDurationMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":             {},
+		"truncated string":  {0x02, 0, 10, 'o', 'n'},
+		"oversized string":  {0x02, 0xFF, 0xFF},
+		"truncated array":   append([]byte{0x02, 0, 1, 'x', 0x08}, 0, 0),
+		"truncated key":     {0x02, 0, 1, 'x', 0x03, 0, 8, 'd', 'u', 'r'},
+		"truncated value":   {0x02, 0, 1, 'x', 0x03, 0, 1, 'a', 0x02, 0},
+		"truncated number":  append([]byte{0x02, 0, 1, 'x', 0x03, 0, 8}, append([]byte("duration"), 0x00, 0x40)...),
+		"key without value": append([]byte{0x02, 0, 1, 'x', 0x03, 0, 8}, "duration"...),
+	}
+	for name, data := range tests {
+		if duration, ok := amfDuration(data); ok {
+			t.Errorf("amfDuration %s should not have found a duration: %f", name, duration)
+		}
+	}
+}
+
+func TestVideoUploaderLimits(t *testing.T) {
+	uploads := newFakeUploads()
+	server := httptest.NewServer(uploads)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "twitch-validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := writeTestVideo(t, dir, "video.txt", []byte("not a video at all"))
+
+	// The file is checked without any Limits set
+	uploader := newFakeUploadClient(server).NewVideoUploader()
+
+	_, err = uploader.UploadFile(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, path)
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) {
+		t.Errorf("UploadFile the validation error was not returned: %v", err)
+	}
+	_, err = uploader.UploadFileResumable(context.Background(), &CreateVideoInput{ChannelID: 1234, Title: "Test"}, path, filepath.Join(dir, "video.session"))
+	if !errors.As(err, &validationError) {
+		t.Errorf("UploadFileResumable the validation error was not returned: %v", err)
+	}
+	if uploads.created != 0 {
+		t.Errorf("The invalid video should not have been created: %d", uploads.created)
+	}
+}
@@ -0,0 +1,87 @@
+// Package twitchotel traces the endpoint calls of a twitch.Client with OpenTelemetry
+package twitchotel
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/ollieparsley/twitchy-gopher/twitch"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName the name of the tracer spans are created with
+const instrumentationName = "github.com/ollieparsley/twitchy-gopher/twitchotel"
+
+//Tracer a twitch.Tracer that creates an OpenTelemetry span named after the endpoint for every call
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+//NewTracer create a tracer using the tracer provider and propagator, nil uses the global ones from otel
+func NewTracer(provider trace.TracerProvider, propagator propagation.TextMapPropagator) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+	return &Tracer{
+		tracer:     provider.Tracer(instrumentationName),
+		propagator: propagator,
+	}
+}
+
+// Start - start a client span for the endpoint call and propagate it in the request headers,
+// a call made of several endpoint calls, without a request, gets an internal span they are children of
+func (t *Tracer) Start(ctx context.Context, endpoint string, req *http.Request) (context.Context, twitch.Span) {
+	if req == nil {
+		ctx, span := t.tracer.Start(ctx, endpoint,
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attribute.String("twitch.endpoint", endpoint)),
+		)
+		return ctx, &endpointSpan{span: span}
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.String("twitch.endpoint", endpoint),
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Hostname()),
+		attribute.String("url.path", req.URL.Path),
+	}
+	for name, id := range twitch.RequestIDs(req) {
+		attributes = append(attributes, attribute.String("twitch."+name, id))
+	}
+
+	ctx, span := t.tracer.Start(ctx, endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return ctx, &endpointSpan{span: span}
+}
+
+//endpointSpan the span of an endpoint call
+type endpointSpan struct {
+	span trace.Span
+}
+
+// End - record the status, rate limit and error of the call and end the span
+func (s *endpointSpan) End(resp *http.Response, err error) {
+	if resp != nil {
+		s.span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if remaining, parseErr := strconv.Atoi(resp.Header.Get("Ratelimit-Remaining")); parseErr == nil {
+			s.span.SetAttributes(attribute.Int("twitch.ratelimit.remaining", remaining))
+		}
+	}
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,124 @@
+package twitchotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ollieparsley/twitchy-gopher/twitch"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracer() (*Tracer, *tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return NewTracer(provider, propagation.TraceContext{}), exporter, provider
+}
+
+func spanAttribute(span tracetest.SpanStub, key string) attribute.Value {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracer(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		w.Header().Set("Ratelimit-Remaining", "799")
+		w.Write([]byte(`{"_total":1,"follows":[]}`))
+	}))
+	defer server.Close()
+
+	tracer, exporter, provider := newTestTracer()
+	client := twitch.NewClient(&twitch.OAuthConfig{}, nil, twitch.WithAPIURL(server.URL), twitch.WithTracer(tracer))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, errorOutput := client.GetChannelFollowersWithContext(ctx, &twitch.GetChannelFollowersInput{ChannelID: 1234})
+	parent.End()
+	if errorOutput != nil {
+		t.Errorf("GetChannelFollowers errorOutput should have been nil: %+v", errorOutput)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("The endpoint and parent spans were not exported: %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GetChannelFollowers" {
+		t.Errorf("The span name was not GetChannelFollowers: %s", span.Name)
+	}
+	if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("The span was not a child of the span in the context")
+	}
+	if spanAttribute(span, "twitch.channel_id").AsString() != "1234" {
+		t.Errorf("The channel ID was not recorded: %v", span.Attributes)
+	}
+	if spanAttribute(span, "http.response.status_code").AsInt64() != 200 {
+		t.Errorf("The status code was not recorded: %v", span.Attributes)
+	}
+	if spanAttribute(span, "twitch.ratelimit.remaining").AsInt64() != 799 {
+		t.Errorf("The rate limit remaining was not recorded: %v", span.Attributes)
+	}
+	if traceparent == "" || traceparent[36:52] != span.SpanContext.SpanID().String() {
+		t.Errorf("The span was not propagated in the request: %s", traceparent)
+	}
+}
+
+func TestTracerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		w.Write([]byte(`{"error":"Not Found","status":404,"message":"Channel not found"}`))
+	}))
+	defer server.Close()
+
+	tracer, exporter, _ := newTestTracer()
+	client := twitch.NewClient(&twitch.OAuthConfig{}, nil, twitch.WithAPIURL(server.URL), twitch.WithTracer(tracer))
+
+	client.GetChannelByID(&twitch.GetChannelByIDInput{ChannelID: 1234})
+
+	span := exporter.GetSpans()[0]
+	if span.Status.Code != codes.Error {
+		t.Errorf("The span status was not an error: %v", span.Status)
+	}
+	if spanAttribute(span, "http.response.status_code").AsInt64() != 404 {
+		t.Errorf("The status code was not recorded: %v", span.Attributes)
+	}
+	if len(span.Events) != 1 || span.Events[0].Name != "exception" {
+		t.Errorf("The error was not recorded: %v", span.Events)
+	}
+}
+
+func TestTracerCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"_total":0,"follows":[]}`))
+	}))
+	defer server.Close()
+
+	tracer, exporter, _ := newTestTracer()
+	client := twitch.NewClient(&twitch.OAuthConfig{}, nil, twitch.WithAPIURL(server.URL), twitch.WithTracer(tracer))
+
+	client.GetChannelFollowersPages(&twitch.GetChannelFollowersInput{ChannelID: 1234}, func(page *twitch.GetChannelFollowersOutput, lastPage bool) bool {
+		return true
+	})
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[1].Name != "GetChannelFollowersPages" {
+		t.Fatalf("The span of the pages was not exported after the page: %d", len(spans))
+	}
+	if spans[1].SpanKind != trace.SpanKindInternal {
+		t.Errorf("The span of the pages was not internal: %v", spans[1].SpanKind)
+	}
+	if spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Error("The span of the page was not a child of the span of the pages")
+	}
+}
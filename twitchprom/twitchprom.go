@@ -0,0 +1,59 @@
+// Package twitchprom records the metrics of a twitch.Client in Prometheus
+package twitchprom
+
+import (
+	"github.com/ollieparsley/twitchy-gopher/twitch"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//Collector a twitch.Metrics that is also a prometheus.Collector, register it and give it to the client with twitch.WithMetrics
+type Collector struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+}
+
+//NewCollector create the request metrics, namespace is the optional prefix of the metric names
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "twitch_requests_total",
+			Help:      "The number of Twitch API requests by endpoint and status class.",
+		}, []string{"endpoint", "status_class"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "twitch_request_duration_seconds",
+			Help:      "The duration of Twitch API requests including retries by endpoint and status class.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "status_class"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "twitch_request_retries_total",
+			Help:      "The number of times Twitch API requests were retried by endpoint.",
+		}, []string{"endpoint"}),
+	}
+}
+
+// ObserveRequest - record the outcome of a request
+func (c *Collector) ObserveRequest(metric *twitch.RequestMetric) {
+	c.requests.WithLabelValues(metric.Endpoint, metric.StatusClass).Inc()
+	c.duration.WithLabelValues(metric.Endpoint, metric.StatusClass).Observe(metric.Duration.Seconds())
+	if metric.Retries > 0 {
+		c.retries.WithLabelValues(metric.Endpoint).Add(float64(metric.Retries))
+	}
+}
+
+// Describe - describe the request metrics to Prometheus
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.retries.Describe(ch)
+}
+
+// Collect - collect the request metrics for Prometheus
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.retries.Collect(ch)
+}
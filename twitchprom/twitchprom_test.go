@@ -0,0 +1,57 @@
+package twitchprom
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ollieparsley/twitchy-gopher/twitch"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector("test")
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	collector.ObserveRequest(&twitch.RequestMetric{Endpoint: "GetChannelFollowers", StatusClass: "2xx", Duration: 100 * time.Millisecond})
+	collector.ObserveRequest(&twitch.RequestMetric{Endpoint: "GetChannelFollowers", StatusClass: "2xx", Duration: 200 * time.Millisecond})
+	collector.ObserveRequest(&twitch.RequestMetric{Endpoint: "UploadVideoPart", StatusClass: "5xx", Retries: 2, Duration: time.Second})
+
+	expected := `
+# HELP test_twitch_requests_total The number of Twitch API requests by endpoint and status class.
+# TYPE test_twitch_requests_total counter
+test_twitch_requests_total{endpoint="GetChannelFollowers",status_class="2xx"} 2
+test_twitch_requests_total{endpoint="UploadVideoPart",status_class="5xx"} 1
+# HELP test_twitch_request_retries_total The number of times Twitch API requests were retried by endpoint.
+# TYPE test_twitch_request_retries_total counter
+test_twitch_request_retries_total{endpoint="UploadVideoPart"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "test_twitch_requests_total", "test_twitch_request_retries_total"); err != nil {
+		t.Errorf("The collected metrics were not correct: %s", err)
+	}
+
+	if count := testutil.CollectAndCount(collector, "test_twitch_request_duration_seconds"); count != 2 {
+		t.Errorf("The duration histograms were not 2: %d", count)
+	}
+}
+
+func TestCollectorClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		w.Write([]byte(`{"error":"Not Found","status":404,"message":"Channel not found"}`))
+	}))
+	defer server.Close()
+
+	collector := NewCollector("")
+	client := twitch.NewClient(&twitch.OAuthConfig{}, nil, twitch.WithAPIURL(server.URL), twitch.WithMetrics(collector))
+
+	client.GetChannel()
+
+	if value := testutil.ToFloat64(collector.requests.WithLabelValues("GetChannel", "4xx")); value != 1 {
+		t.Errorf("The 404 was not counted: %v", value)
+	}
+}